@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/dk872/architecture-lab3/painter/lang/lsp"
+)
+
+var scriptsDir = flag.String("scripts", "", "directory with scripts available to the include command")
+
+// Сервер мови painter для редакторів: спілкується з редактором через stdin та stdout.
+func main() {
+	flag.Parse()
+	log.SetOutput(os.Stderr)
+
+	var server lsp.Server
+	if *scriptsDir != "" {
+		server.Scripts = os.DirFS(*scriptsDir)
+	}
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("Language server stopped: %s", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+// makeFIFO не підтримується без іменованих каналів POSIX
+func makeFIFO(string) error {
+	return errors.New("named pipes are not supported on this platform")
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// makeFIFO створює іменований канал, доступний лише власнику, якщо його ще немає
+func makeFIFO(path string) error {
+	fi, err := os.Stat(path)
+	switch {
+	case err == nil && fi.Mode()&fs.ModeNamedPipe == 0:
+		return fmt.Errorf("%s exists and is not a named pipe", path)
+	case err == nil:
+		return nil
+	case !os.IsNotExist(err):
+		return err
+	}
+	return syscall.Mkfifo(path, 0o600)
+}
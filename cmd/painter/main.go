@@ -1,33 +1,204 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/dk872/architecture-lab3/painter"
 	"github.com/dk872/architecture-lab3/painter/lang"
 	"github.com/dk872/architecture-lab3/ui"
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+)
+
+var (
+	journalPath = flag.String("journal", "", "append every accepted script to this journal file")
+	replayPath  = flag.String("replay", "", "replay scripts from this journal file")
+	replaySpeed = flag.Float64("speed", 1, "replay speed factor (0 replays without delays)")
+	replayStep  = flag.Bool("step", false, "replay the journal step by step, waiting for Enter before each script")
+	scriptsDir  = flag.String("scripts", "", "directory with scripts available to the include command")
+	recordDir   = flag.String("recordings", "recordings", "directory for recordings of the main canvas started at /record/start (frames can only be read with -headless)")
+	headless    = flag.Bool("headless", false, "run without a window and show the canvas in the browser at /view/")
+	tokensPath  = flag.String("tokens", "", "file with access tokens; when empty, requests are not authenticated")
+
+	addr         = flag.String("addr", "localhost:17000", "address of the HTTP server")
+	readTimeout  = flag.Duration("read-timeout", lang.DefaultReadTimeout, "maximum time to read a request including its body")
+	writeTimeout = flag.Duration("write-timeout", lang.DefaultWriteTimeout, "maximum time to write a response (event and frame streams are not limited)")
+	idleTimeout  = flag.Duration("idle-timeout", lang.DefaultIdleTimeout, "how long to keep idle keep-alive connections open")
+	maxBody      = flag.Int64("max-body", lang.DefaultMaxBodySize, "maximum size of a script in a request body, in bytes")
+	maxLines     = flag.Int("max-lines", 10000, "maximum number of lines in a script (0 disables the limit)")
+	maxCanvases  = flag.Int("max-canvases", lang.DefaultMaxCanvases, "maximum number of canvases including the main one")
+	tlsCert      = flag.String("tls-cert", "", "PEM certificate file; serve HTTPS when set together with -tls-key")
+	tlsKey       = flag.String("tls-key", "", "PEM private key file for -tls-cert")
+
+	unixPath  = flag.String("unix", "", "also accept scripts on this Unix socket; a script ends with a NUL byte or the end of the connection")
+	fifoPath  = flag.String("fifo", "", "also read scripts from this named pipe; a script ends with a NUL byte or when the writer closes the pipe; created if missing")
+	fromStdin = flag.Bool("stdin", false, "also read scripts from standard input, separated by NUL bytes, and print results")
 )
 
 func main() {
+	flag.Parse()
+	switch flag.Arg(0) {
+	case "fmt":
+		os.Exit(formatScripts(flag.Args()[1:]))
+	case "lint":
+		os.Exit(lintScripts(flag.Args()[1:]))
+	}
+
 	var (
-		pv ui.Visualizer // Візуалізатор створює вікно та малює у ньому.
+		pv     ui.Visualizer // Візуалізатор створює вікно та малює у ньому.
+		viewer ui.Viewer     // Переглядач полотна у браузері для режиму без вікна.
 
 		// Потрібні для частини 2.
 		opLoop painter.Loop // Цикл обробки команд.
 		parser lang.Parser  // Парсер команд.
+		assets lang.Assets  // Завантажені зображення.
+		events lang.Events  // Потік подій для панелей моніторингу.
+
+		canvases lang.Canvases // Іменовані полотна; головне полотно використовує opLoop та parser.
 	)
 
 	//pv.Debug = true
 	pv.Title = "Simple painter"
 
-	pv.OnScreenReady = opLoop.Start
-	opLoop.Receiver = &pv
+	pv.OnScreenReady = canvases.Start
+	// Tab перемикає вікно на наступне полотно. Перемикання чекає, поки вікно прийме поточний кадр, тому воно не
+	// виконується у горутині подій вікна.
+	pv.OnKey = func(e key.Event) {
+		if e.Code == key.CodeTab && e.Direction == key.DirPress {
+			go canvases.ShowNext()
+		}
+	}
+	// Події кадрів публікуються синхронно, а вікно та переглядач отримують кадри показаного полотна незалежно від циклу
+	opLoop.Receiver = events.Frames(nil)
+	if *headless {
+		canvases.Display = &viewer
+	} else {
+		canvases.Display = &pv
+	}
+	parser.Assets = &assets
+	parser.MaxLines = *maxLines
+	if *scriptsDir != "" {
+		parser.Scripts = os.DirFS(*scriptsDir)
+	}
+	canvases.Assets, canvases.Scripts = parser.Assets, parser.Scripts
+	canvases.MaxLines, canvases.MaxBodySize, canvases.MaxCanvases = *maxLines, *maxBody, *maxCanvases
+
+	handler := &lang.Handler{Loop: &opLoop, Parser: &parser, Events: &events, MaxBodySize: *maxBody}
+	if *journalPath != "" {
+		journal, err := lang.OpenJournal(*journalPath)
+		if err != nil {
+			log.Fatalf("Failed to open journal: %s", err)
+		}
+		defer journal.Close()
+		handler.Journal = journal
+	}
+	if err := canvases.Add(&lang.Canvas{Name: "main", Loop: &opLoop, Parser: &parser, Handler: handler}); err != nil {
+		log.Fatalf("Failed to add the main canvas: %s", err)
+	}
+
+	if *replayPath != "" {
+		entries, err := readJournal(*replayPath)
+		if err != nil {
+			log.Fatalf("Failed to read journal: %s", err)
+		}
+		pv.OnScreenReady = func(s screen.Screen) {
+			canvases.Start(s)
+			go replay(&opLoop, &parser, entries)
+		}
+	}
+
+	var auth *lang.Auth // Без файлу токенів перевірка вимкнена
+	if *tokensPath != "" {
+		var err error
+		if auth, err = lang.LoadAuth(*tokensPath); err != nil {
+			log.Fatalf("Failed to load access tokens: %s", err)
+		}
+	}
+
+	// Локальні транспорти виконують скрипти так само, як HTTP запити до головного полотна
+	if *fromStdin {
+		if *replayStep {
+			log.Fatal("-stdin cannot be used with -step: both read standard input")
+		}
+		go serveStdin(handler)
+	}
+	if *fifoPath != "" {
+		go serveFIFO(handler, *fifoPath)
+	}
+	if *unixPath != "" {
+		l, err := listenUnix(*unixPath)
+		if err != nil {
+			log.Fatalf("Failed to listen on Unix socket: %s", err)
+		}
+		defer l.Close()
+		go serveUnix(handler, l)
+	}
+
+	server := lang.NewServer(*addr, http.DefaultServeMux)
+	server.ReadTimeout, server.WriteTimeout, server.IdleTimeout = *readTimeout, *writeTimeout, *idleTimeout
+	server.CertFile, server.KeyFile = *tlsCert, *tlsKey
 
 	go func() {
-		http.Handle("/", lang.HttpHandler(&opLoop, &parser))
-		_ = http.ListenAndServe("localhost:17000", nil)
+		http.Handle("/", auth.Protect(lang.ScopeWrite, handler))
+		http.Handle("/ws", auth.Protect(lang.ScopeWrite, lang.WebSocketHandler(handler)))
+		http.Handle("/events", auth.Protect(lang.ScopeRead, &events))
+		http.Handle("/canvas", auth.ProtectMethods(&canvases))
+		http.Handle("/canvas/", auth.ProtectMethods(&canvases))
+		http.Handle("/svg", auth.Protect(lang.ScopeRead, lang.SVGHandler(&opLoop, &parser)))
+		http.Handle("/assets/{name}", auth.ProtectMethods(lang.AssetsHandler(&assets)))
+		http.Handle("/view/", auth.Protect(lang.ScopeRead, http.StripPrefix("/view", &viewer)))
+		http.Handle("/record/", auth.Protect(lang.ScopeWrite, http.StripPrefix("/record", &lang.Recordings{Loop: &opLoop, Parser: &parser, Dir: *recordDir})))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server failed: %s", err)
+		}
 	}()
 
-	pv.Main()
+	if *headless {
+		// Без вікна програма працює до сигналу завершення
+		pv.OnScreenReady(ui.Headless{})
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		<-ctx.Done()
+		stop()
+	} else {
+		pv.Main()
+	}
+	_ = server.Close()
 	opLoop.StopAndWait()
 }
+
+func readJournal(path string) ([]lang.JournalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return lang.ReadJournal(f)
+}
+
+// replay відтворює журнал у циклі подій відповідно до прапорців командного рядка.
+func replay(loop *painter.Loop, parser *lang.Parser, entries []lang.JournalEntry) {
+	r := lang.Replayer{Loop: loop, Parser: parser, Speed: *replaySpeed}
+	if *replayStep {
+		stdin := bufio.NewReader(os.Stdin)
+		r.Step = func(e lang.JournalEntry) error {
+			fmt.Printf("%s %s\n%s\n[Enter] ", e.Time.Format("15:04:05.000"), e.Remote, e.Script)
+			_, err := stdin.ReadString('\n')
+			return err
+		}
+	}
+
+	if err := r.Replay(entries); err != nil {
+		log.Printf("Replay stopped: %s", err)
+		return
+	}
+	log.Printf("Replayed %d scripts", len(entries))
+}
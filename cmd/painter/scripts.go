@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dk872/architecture-lab3/painter/lang"
+)
+
+// formatScripts виконує команду fmt: форматує файли скриптів або стандартне введення, якщо файли не задані.
+// Повертає код завершення програми.
+func formatScripts(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := fs.Bool("w", false, "write the result to the source files instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		res, err := lang.Format(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Print(res)
+		return 0
+	}
+
+	code := 0
+	for _, path := range fs.Args() {
+		if err := formatFile(path, *write); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+func formatFile(path string, write bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	res, err := lang.Format(strings.NewReader(string(src)))
+	if err != nil {
+		return err
+	}
+	if !write {
+		fmt.Print(res)
+		return nil
+	}
+	if res == string(src) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(res), 0o644)
+}
+
+// lintScripts виконує команду lint: виводить помилки та попередження файлів скриптів або стандартного введення.
+// Повертає 1, якщо знайдено хоча б одну проблему.
+func lintScripts(paths []string) int {
+	if len(paths) == 0 {
+		return lintScript("<stdin>", os.Stdin)
+	}
+
+	code := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		code = max(code, lintScript(path, f))
+		f.Close()
+	}
+	return code
+}
+
+func lintScript(name string, in io.Reader) int {
+	// Кожен файл перевіряється окремим парсером, щоб фігури одного скрипта не впливали на попередження іншого
+	parser := &lang.Parser{}
+	if *scriptsDir != "" {
+		parser.Scripts = os.DirFS(*scriptsDir)
+	}
+
+	problems := parser.Check(in)
+	for _, e := range problems {
+		fmt.Printf("%s: %v\n", name, e)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "net"
+
+// listenSocket створює сокет; права доступу до нього визначає каталог, у якому він створюється
+func listenSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenSocket створює сокет з правами 0600. Маска прав встановлюється до створення файлу, тому сокет ні на мить
+// не буде доступним іншим користувачам.
+func listenSocket(path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
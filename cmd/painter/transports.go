@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+
+	"github.com/dk872/architecture-lab3/painter/lang"
+)
+
+// listenUnix створює сокет Unix, доступний лише власнику процесу. Сокет, що залишився від попереднього запуску,
+// видаляється.
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	// Команди через сокет не проходять перевірку токенів, тому доступ обмежується правами файлу
+	return listenSocket(path)
+}
+
+// serveUnix обслуговує з'єднання з сокета, доки його не закрито
+func serveUnix(h *lang.Handler, l net.Listener) {
+	if err := h.Serve(l); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("Unix socket stopped: %s", err)
+	}
+}
+
+// serveStdin виконує команди зі стандартного введення та пише відповіді у стандартне виведення
+func serveStdin(h *lang.Handler) {
+	if err := h.ServeStream("stdin", os.Stdin, os.Stdout); err != nil {
+		log.Printf("Failed to read commands from stdin: %s", err)
+		return
+	}
+	log.Printf("Standard input closed")
+}
+
+// serveFIFO виконує команди з іменованого каналу. Коли всі записувачі закривають канал, він відкривається знову
+// і чекає наступного записувача.
+func serveFIFO(h *lang.Handler, path string) {
+	if err := makeFIFO(path); err != nil {
+		log.Fatalf("Failed to create named pipe: %s", err)
+	}
+	for {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Printf("Failed to open named pipe: %s", err)
+			return
+		}
+		if err := h.ServeStream("fifo:"+path, f, nil); err != nil {
+			log.Printf("Failed to read commands from %s: %s", path, err)
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,168 @@
+package painter
+
+import (
+	"math"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Easing відображає прогрес анімації з діапазону [0, 1] у прогрес руху.
+type Easing func(t float64) float64
+
+// Linear рівномірний рух.
+func Linear(t float64) float64 { return t }
+
+// EaseIn рух із плавним розгоном.
+func EaseIn(t float64) float64 { return t * t * t }
+
+// EaseOut рух із плавним гальмуванням.
+func EaseOut(t float64) float64 { return 1 - math.Pow(1-t, 3) }
+
+// EaseInOut рух із плавним розгоном та гальмуванням.
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// Bounce рух, який наприкінці кілька разів «відскакує» від кінцевої точки.
+func Bounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// Easings містить функції згладжування, доступні за назвою.
+var Easings = map[string]Easing{
+	"linear":      Linear,
+	"ease-in":     EaseIn,
+	"ease-out":    EaseOut,
+	"ease-in-out": EaseInOut,
+	"bounce":      Bounce,
+}
+
+// frameInterval інтервал між кадрами анімації
+const frameInterval = time.Second / 30
+
+// AnimateOperation плавно переміщує фігури у нову позицію за заданий час. Операція обробляється циклом подій, який
+// сам генерує проміжні кадри; виклик Do поза циклом нічого не робить.
+type AnimateOperation struct {
+	Figures  []*FigureOperation // Фігури, які потрібно перемістити
+	X, Y     float64            // Кінцева позиція
+	Duration time.Duration      // Тривалість анімації
+	Easing   Easing             // Функція згладжування, Linear якщо не задана
+
+	// Frame повертає операцію, яка перемальовує сцену для кожного кадру та сигналізує про готовність текстури.
+	Frame func() Operation
+}
+
+func (op *AnimateOperation) Do(t screen.Texture) bool { return false }
+
+// StopAnimationsOp зупиняє всі анімації, які виконує цикл подій, наприклад коли фігури прибрано зі сцени.
+var StopAnimationsOp = stopAnimationsOp{}
+
+type stopAnimationsOp struct{}
+
+func (op stopAnimationsOp) Do(t screen.Texture) bool { return false }
+
+// animation стан анімації, яка виконується циклом подій
+type animation struct {
+	op           *AnimateOperation
+	fromX, fromY []float64
+	start        time.Time
+	cancelled    bool
+}
+
+// targets перевіряє, чи переміщує анімація хоча б одну з фігур
+func (a *animation) targets(figures []*FigureOperation) bool {
+	for _, f := range a.op.Figures {
+		for _, other := range figures {
+			if f == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// animate запускає нову анімацію, скасовуючи попередні анімації тих самих фігур
+func (l *Loop) animate(op *AnimateOperation) {
+	l.cancelAnimations(op.Figures)
+
+	a := &animation{op: op, start: l.mq.now()}
+	for _, f := range op.Figures {
+		a.fromX = append(a.fromX, f.X)
+		a.fromY = append(a.fromY, f.Y)
+	}
+	l.animations = append(l.animations, a)
+	l.PostAfter(frameInterval, animationFrame{l: l, a: a})
+}
+
+// cancelAnimations зупиняє анімації, які переміщують хоча б одну з фігур
+func (l *Loop) cancelAnimations(figures []*FigureOperation) {
+	l.stopAnimations(func(a *animation) bool { return a.targets(figures) })
+}
+
+// stopAnimations зупиняє анімації, для яких виконується умова
+func (l *Loop) stopAnimations(cond func(a *animation) bool) {
+	active := l.animations[:0]
+	for _, a := range l.animations {
+		if cond(a) {
+			a.cancelled = true
+		} else {
+			active = append(active, a)
+		}
+	}
+	clear(l.animations[len(active):])
+	l.animations = active
+}
+
+// animationFrame операція, яка обчислює та малює один кадр анімації
+type animationFrame struct {
+	l *Loop
+	a *animation
+}
+
+func (op animationFrame) Do(t screen.Texture) bool {
+	a := op.a
+	if a.cancelled {
+		return false
+	}
+
+	progress := 1.0
+	if a.op.Duration > 0 {
+		progress = math.Min(float64(op.l.mq.now().Sub(a.start))/float64(a.op.Duration), 1)
+	}
+	easing := a.op.Easing
+	if easing == nil {
+		easing = Linear
+	}
+	k := easing(progress)
+	for i, f := range a.op.Figures {
+		f.X = a.fromX[i] + (a.op.X-a.fromX[i])*k
+		f.Y = a.fromY[i] + (a.op.Y-a.fromY[i])*k
+	}
+
+	if progress >= 1 {
+		op.l.stopAnimations(func(other *animation) bool { return other == a })
+	} else {
+		op.l.PostAfter(frameInterval, op)
+	}
+	if a.op.Frame == nil {
+		return false
+	}
+	return op.l.do(a.op.Frame())
+}
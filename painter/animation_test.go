@@ -0,0 +1,91 @@
+package painter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func TestEasings_Bounds(t *testing.T) {
+	for name, easing := range Easings {
+		if v := easing(0); math.Abs(v) > 1e-9 {
+			t.Errorf("%s(0) = %v, want 0", name, v)
+		}
+		if v := easing(1); math.Abs(v-1) > 1e-9 {
+			t.Errorf("%s(1) = %v, want 1", name, v)
+		}
+	}
+}
+
+func TestEasings_InOut(t *testing.T) {
+	// Розгін відстає від рівномірного руху, гальмування випереджає його, а разом вони дають EaseInOut
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.8} {
+		if in, out := EaseIn(x), EaseOut(x); in >= x || out <= x {
+			t.Errorf("At %v expected ease-in below and ease-out above linear, got %v and %v", x, in, out)
+		}
+		if v, want := EaseInOut(x/2), EaseIn(x)/2; math.Abs(v-want) > 1e-9 {
+			t.Errorf("EaseInOut(%v) = %v, want %v", x/2, v, want)
+		}
+		if v, want := EaseInOut(0.5+x/2), 0.5+EaseOut(x)/2; math.Abs(v-want) > 1e-9 {
+			t.Errorf("EaseInOut(%v) = %v, want %v", 0.5+x/2, v, want)
+		}
+	}
+}
+
+func TestLoop_Animate(t *testing.T) {
+	var (
+		l  Loop
+		tr testReceiver
+	)
+	l.Receiver = &tr
+	l.stop = make(chan struct{})
+	l.Start(mockScreen{})
+
+	fig := &FigureOperation{X: 0, Y: 0}
+	done := make(chan struct{})
+	l.Post(&AnimateOperation{
+		Figures:  []*FigureOperation{fig},
+		X:        100,
+		Y:        200,
+		Duration: 50 * time.Millisecond,
+		Easing:   EaseInOut,
+		Frame: func() Operation {
+			return OperationFunc(func(screen.Texture) {
+				if fig.X == 100 && fig.Y == 200 {
+					close(done)
+				}
+			})
+		},
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Animation did not reach the target position")
+	}
+	l.StopAndWait()
+
+	if len(l.animations) != 0 {
+		t.Errorf("Expected no active animations, got %d", len(l.animations))
+	}
+}
+
+func TestLoop_AnimateCancelledByMove(t *testing.T) {
+	var l Loop
+	l.stop = make(chan struct{})
+	l.Start(mockScreen{})
+
+	figures := []*FigureOperation{{X: 0, Y: 0}}
+	l.Post(&AnimateOperation{Figures: figures, X: 100, Y: 100, Duration: time.Hour})
+	l.Post(&MoveFiguresOperation{X: 10, Y: 20, Figures: &figures})
+	l.StopAndWait()
+
+	if figures[0].X != 10 || figures[0].Y != 20 {
+		t.Errorf("Expected figure to be moved, got %+v", figures[0])
+	}
+	if len(l.animations) != 0 {
+		t.Errorf("Expected animation to be cancelled, got %d active", len(l.animations))
+	}
+}
@@ -0,0 +1,40 @@
+package painter
+
+import (
+	"image/color"
+	"math"
+)
+
+// FigureShape описує фігуру як набір частин, координати яких задаються у пікселях відносно центру фігури.
+type FigureShape []FigurePart
+
+// FigurePart частина складеної фігури - многокутник зі своїм кольором.
+type FigurePart struct {
+	Points []Point
+	Color  color.Color // Колір частини; nil означає колір фігури
+}
+
+// ellipseSegments кількість сторін многокутника, яким наближається еліпс у складеній фігурі
+const ellipseSegments = 64
+
+// RectPart повертає прямокутну частину фігури з кутами (x1, y1) та (x2, y2).
+func RectPart(x1, y1, x2, y2 float64, c color.Color) FigurePart {
+	return FigurePart{Points: []Point{{x1, y1}, {x2, y1}, {x2, y2}, {x1, y2}}, Color: c}
+}
+
+// EllipsePart повертає частину фігури у вигляді еліпса з центром (cx, cy) та радіусами rx, ry.
+func EllipsePart(cx, cy, rx, ry float64, c color.Color) FigurePart {
+	pts := make([]Point, ellipseSegments)
+	for i := range pts {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / ellipseSegments)
+		pts[i] = Point{cx + rx*cos, cy + ry*sin}
+	}
+	return FigurePart{Points: pts, Color: c}
+}
+
+// DefaultFigure стандартна T-подібна фігура, яка малюється, якщо форму не задано. Розміри задані для полотна
+// 800×800 і пропорційно змінюються разом з розміром полотна.
+var DefaultFigure = FigureShape{
+	RectPart(-200, -150, 200, 0, nil),
+	RectPart(-80, 0, 80, 150, nil),
+}
@@ -0,0 +1,212 @@
+package painter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// Gradient описує плавний перехід між двома кольорами. Лінійний градієнт розтягується на обмежувальний
+// прямокутник заповнюваної області, радіальний задається центром та радіусом у пікселях.
+type Gradient struct {
+	From, To  color.Color
+	Radial    bool
+	Angle     float64 // Напрям лінійного градієнта у градусах: 0 - зліва направо, 90 - згори донизу
+	CX, CY, R float64 // Центр та радіус радіального градієнта
+}
+
+// direction повертає одиничний вектор напряму лінійного градієнта
+func (g Gradient) direction() (dx, dy float64) {
+	rad := g.Angle * math.Pi / 180
+	return math.Cos(rad), math.Sin(rad)
+}
+
+// extent повертає мінімальну та максимальну проєкції кутів прямокутника на напрям лінійного градієнта
+func (g Gradient) extent(box image.Rectangle) (pmin, pmax float64) {
+	dx, dy := g.direction()
+	pmin, pmax = math.Inf(1), math.Inf(-1)
+	for _, c := range []image.Point{box.Min, {box.Max.X, box.Min.Y}, {box.Min.X, box.Max.Y}, box.Max} {
+		p := float64(c.X)*dx + float64(c.Y)*dy
+		pmin, pmax = math.Min(pmin, p), math.Max(pmax, p)
+	}
+	return
+}
+
+// render обчислює кольори градієнта для пікселів області area, вважаючи box обмежувальним прямокутником фігури.
+// Координати отриманого зображення збігаються з координатами текстури.
+func (g Gradient) render(box, area image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(area)
+	from := color.NRGBAModel.Convert(shapeColor(g.From)).(color.NRGBA)
+	to := color.NRGBAModel.Convert(shapeColor(g.To)).(color.NRGBA)
+	dx, dy := g.direction()
+	pmin, pmax := g.extent(box)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+
+			var k float64
+			if g.Radial {
+				if g.R > 0 {
+					k = math.Hypot(px-g.CX, py-g.CY) / g.R
+				}
+			} else if pmax > pmin {
+				k = (px*dx + py*dy - pmin) / (pmax - pmin)
+			}
+			k = math.Max(0, math.Min(1, k))
+
+			// Пікселі записуються напряму у Pix з попередньо помноженою прозорістю, як це зробив би img.Set
+			r, g, b, a := color.NRGBA{
+				R: lerp(from.R, to.R, k), G: lerp(from.G, to.G, k), B: lerp(from.B, to.B, k), A: lerp(from.A, to.A, k),
+			}.RGBA()
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8)
+		}
+	}
+	return img
+}
+
+// maxGradientCache максимальна кількість градієнтів фону, відрендерених наперед
+const maxGradientCache = 16
+
+// gradientKey визначає відрендерений фон: градієнт з кольорами, зведеними до порівнюваного типу, та межі текстури
+type gradientKey struct {
+	from, to  color.NRGBA
+	radial    bool
+	angle     float64
+	cx, cy, r float64
+	bounds    image.Rectangle
+}
+
+var (
+	gradientsMu sync.Mutex
+	gradients   = map[gradientKey]*image.RGBA{}
+)
+
+// cachedRender повертає градієнт, відрендерений на всю область bounds. Фон перемальовується під час кожного
+// розбору скрипта та кожного кадру анімації, тому результат зберігається і використовується лише для читання.
+func (g Gradient) cachedRender(bounds image.Rectangle) *image.RGBA {
+	key := gradientKey{
+		from:   color.NRGBAModel.Convert(shapeColor(g.From)).(color.NRGBA),
+		to:     color.NRGBAModel.Convert(shapeColor(g.To)).(color.NRGBA),
+		radial: g.Radial, angle: g.Angle, cx: g.CX, cy: g.CY, r: g.R,
+		bounds: bounds,
+	}
+	gradientsMu.Lock()
+	defer gradientsMu.Unlock()
+	if img, ok := gradients[key]; ok {
+		return img
+	}
+	if len(gradients) >= maxGradientCache {
+		clear(gradients)
+	}
+	img := g.render(bounds, bounds)
+	gradients[key] = img
+	return img
+}
+
+func lerp(a, b uint8, k float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*k))
+}
+
+// fillGradient заповнює фігуру градієнтом. Градієнт рендериться на CPU для обмежувального прямокутника box; якщо
+// доступний s, результат завантажується у буфер, і в текстуру переносяться лише відрізки, що належать фігурі.
+// Напівпрозорий градієнт у режимі Over малюється через Fill, бо завантаження буфера замінює вміст текстури.
+func fillGradient(s screen.Screen, t screen.Texture, box image.Rectangle, spans spanFunc, g Gradient, st Style) {
+	area := box.Intersect(t.Bounds())
+	if area.Empty() {
+		return
+	}
+	img := g.render(box, area)
+	if k := st.opacity(); k != 1 {
+		// Кольори зберігаються з попередньо помноженою прозорістю, тому множимо всі канали.
+		for i := range img.Pix {
+			img.Pix[i] = uint8(float64(img.Pix[i]) * k)
+		}
+	}
+
+	var buf screen.Buffer
+	if s != nil && (st.Blend == screen.Src || img.Opaque()) {
+		if b, err := s.NewBuffer(area.Size()); err == nil {
+			buf = b
+			defer buf.Release()
+			draw.Draw(buf.RGBA(), buf.Bounds(), img, area.Min, draw.Src)
+		}
+	}
+
+	forEachSpan(area, float64(box.Min.Y), float64(box.Max.Y), spans, func(r image.Rectangle) {
+		if buf != nil {
+			t.Upload(r.Min, buf, r.Sub(area.Min))
+		} else {
+			fillPixels(t, img, r, image.Point{}, st.Blend)
+		}
+	})
+}
+
+// GradientFillOperation зафарбовує всю текстуру градієнтом.
+type GradientFillOperation struct {
+	Gradient Gradient
+}
+
+func (op GradientFillOperation) Do(t screen.Texture) bool {
+	return op.DoScreen(nil, t)
+}
+
+func (op GradientFillOperation) DoScreen(s screen.Screen, t screen.Texture) bool {
+	drawImage(s, t, image.Point{}, op.Gradient.cachedRender(t.Bounds()))
+	return false
+}
+
+func (op GradientFillOperation) WriteSVG(w io.Writer) error {
+	box := image.Rectangle{Max: canvasSize(w)}
+	fill, err := writeSVGGradient(w, op.Gradient, box)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, `  <rect x="0" y="0" width="%d" height="%d" %s/>`+"\n", box.Dx(), box.Dy(), fill)
+	return err
+}
+
+// writeSVGGradient записує визначення градієнта та повертає атрибут заливки, який на нього посилається
+func writeSVGGradient(w io.Writer, g Gradient, box image.Rectangle) (string, error) {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%v %v", g, box)
+	id := fmt.Sprintf("gradient-%08x", h.Sum32())
+
+	from, to := svgStop(shapeColor(g.From)), svgStop(shapeColor(g.To))
+	var err error
+	if g.Radial {
+		_, err = fmt.Fprintf(w, `  <defs><radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%g" cy="%g" r="%g">`+
+			`<stop offset="0" %s/><stop offset="1" %s/></radialGradient></defs>`+"\n", id, g.CX, g.CY, g.R, from, to)
+	} else {
+		dx, dy := g.direction()
+		pmin, pmax := g.extent(box)
+		_, err = fmt.Fprintf(w, `  <defs><linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f">`+
+			`<stop offset="0" %s/><stop offset="1" %s/></linearGradient></defs>`+"\n",
+			id, dx*pmin, dy*pmin, dx*pmax, dy*pmax, from, to)
+	}
+	return fmt.Sprintf(`fill="url(#%s)"`, id), err
+}
+
+// svgStop повертає атрибути кольору для точки градієнта
+func svgStop(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf(`stop-color="#%02x%02x%02x" stop-opacity="%.3f"`, n.R, n.G, n.B, float64(n.A)/0xff)
+}
+
+// boundingBox повертає найменший цілочисельний прямокутник, що містить усі точки
+func boundingBox(pts []Point) image.Rectangle {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range pts {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
@@ -0,0 +1,116 @@
+package painter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestGradientFillOperation_Linear(t *testing.T) {
+	tx := newImageTexture()
+	GradientFillOperation{Gradient: Gradient{From: color.White, To: color.Black}}.Do(tx)
+
+	if c := tx.img.RGBAAt(0, 400); c.R < 0xfe {
+		t.Errorf("Expected left edge to be white, got %v", c)
+	}
+	if c := tx.img.RGBAAt(799, 400); c.R > 1 {
+		t.Errorf("Expected right edge to be black, got %v", c)
+	}
+	if c := tx.img.RGBAAt(400, 0); c.R < 0x7e || c.R > 0x81 || c != tx.img.RGBAAt(400, 799) {
+		t.Errorf("Expected middle column to be uniformly grey, got %v", c)
+	}
+}
+
+func TestGradientFillOperation_Angle(t *testing.T) {
+	tx := newImageTexture()
+	GradientFillOperation{Gradient: Gradient{From: color.White, To: color.Black, Angle: 90}}.DoScreen(bufferScreen{}, tx)
+
+	if tx.uploads != 1 {
+		t.Errorf("Expected gradient to be uploaded once, got %d uploads", tx.uploads)
+	}
+	if top, bottom := tx.img.RGBAAt(400, 0), tx.img.RGBAAt(400, 799); top.R < 0xfe || bottom.R > 1 {
+		t.Errorf("Expected vertical gradient from white to black, got %v and %v", top, bottom)
+	}
+}
+
+func TestEllipseOperation_RadialGradient(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	op := EllipseOperation{X: 100, Y: 100, RX: 50, RY: 50, Gradient: &Gradient{From: red, To: blue, Radial: true, CX: 100, CY: 100, R: 50}}
+
+	uploaded := newImageTexture()
+	op.DoScreen(bufferScreen{}, uploaded)
+	if uploaded.uploads == 0 {
+		t.Error("Expected gradient spans to be uploaded")
+	}
+	checkRadial(t, uploaded)
+
+	filled := newImageTexture()
+	op.Do(filled)
+	checkRadial(t, filled)
+}
+
+func checkRadial(t *testing.T, tx *imageTexture) {
+	t.Helper()
+	if c := tx.img.RGBAAt(100, 100); c.R < 0xf0 || c.B > 0x10 {
+		t.Errorf("Expected center to be red, got %v", c)
+	}
+	if c := tx.img.RGBAAt(149, 100); c.B < 0xf0 {
+		t.Errorf("Expected edge to be blue, got %v", c)
+	}
+	if tx.isSet(140, 140) || tx.isSet(151, 100) {
+		t.Error("Expected pixels outside the circle to stay empty")
+	}
+}
+
+func TestGradient_WriteSVG(t *testing.T) {
+	scene := Scene{Operations: []Operation{
+		GradientFillOperation{Gradient: Gradient{From: color.White, To: color.Black}},
+		PolygonOperation{Points: []Point{{0, 0}, {10, 0}, {0, 10}}, Gradient: &Gradient{Radial: true, CX: 5, CY: 5, R: 5}},
+	}}
+
+	var out strings.Builder
+	if err := scene.WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	svg := out.String()
+	for _, e := range []string{
+		`gradientUnits="userSpaceOnUse" x1="0.00" y1="0.00" x2="800.00" y2="0.00"><stop offset="0" stop-color="#ffffff" stop-opacity="1.000"/>`,
+		`<radialGradient id="gradient-`,
+		`cx="5" cy="5" r="5">`,
+		`fill="url(#gradient-`,
+	} {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Expected %q in SVG output:\n%s", e, svg)
+		}
+	}
+}
+
+func TestGradient_CachedRender(t *testing.T) {
+	g := Gradient{From: color.NRGBA{R: 0xff, A: 0x80}, To: color.NRGBA{B: 0xff, A: 0xff}, Angle: 45}
+	bounds := image.Rect(0, 0, 64, 48)
+	img := g.cachedRender(bounds)
+	if g.cachedRender(bounds) != img {
+		t.Error("Expected the rendered gradient to be reused for the same size")
+	}
+	if g.cachedRender(image.Rect(0, 0, 32, 48)) == img {
+		t.Error("Expected the gradient to be rendered again for another size")
+	}
+
+	// Прямий запис у Pix має давати ті самі кольори, що й Set
+	from, to := g.From.(color.NRGBA), g.To.(color.NRGBA)
+	pmin, pmax := g.extent(bounds)
+	dx, dy := g.direction()
+	want := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			k := ((float64(x)+0.5)*dx + (float64(y)+0.5)*dy - pmin) / (pmax - pmin)
+			want.Set(x, y, color.NRGBA{R: lerp(from.R, to.R, k), G: lerp(from.G, to.G, k), B: lerp(from.B, to.B, k), A: lerp(from.A, to.A, k)})
+		}
+	}
+	if !bytes.Equal(img.Pix, want.Pix) {
+		t.Error("Rendered gradient differs from the one drawn with Set")
+	}
+}
@@ -0,0 +1,124 @@
+package painter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// ScreenOperation реалізується операціями, яким для малювання потрібен доступ до screen.Screen, наприклад, щоб
+// створити буфер і завантажити його вміст у текстуру. Цикл подій викликає DoScreen замість Do.
+type ScreenOperation interface {
+	Operation
+	DoScreen(s screen.Screen, t screen.Texture) (ready bool)
+}
+
+// BlitOperation малює растрове зображення у прямокутнику з лівим верхнім кутом (X, Y) та розмірами W×H.
+// Якщо розміри не задані, використовується власний розмір зображення.
+type BlitOperation struct {
+	Image      image.Image
+	X, Y, W, H float64
+
+	scaled *image.RGBA // зображення, заздалегідь масштабоване до потрібного розміру
+}
+
+// NewBlitOperation створює операцію малювання зображення та одразу масштабує його, щоб не робити цього під час
+// кожного перемальовування сцени.
+func NewBlitOperation(img image.Image, x, y, w, h float64) *BlitOperation {
+	op := &BlitOperation{Image: img, X: x, Y: y, W: w, H: h}
+	op.scaled = op.scaledImage()
+	return op
+}
+
+func (op *BlitOperation) Do(t screen.Texture) bool {
+	return op.DoScreen(nil, t)
+}
+
+func (op *BlitOperation) DoScreen(s screen.Screen, t screen.Texture) bool {
+	if op.Image == nil {
+		return false
+	}
+	drawImage(s, t, image.Pt(int(op.X), int(op.Y)), op.scaledImage())
+	return false
+}
+
+// scaledImage повертає зображення потрібного розміру
+func (op *BlitOperation) scaledImage() *image.RGBA {
+	w, h := int(op.W), int(op.H)
+	if w <= 0 || h <= 0 {
+		w, h = op.Image.Bounds().Dx(), op.Image.Bounds().Dy()
+	}
+	if op.scaled != nil && op.scaled.Bounds().Dx() == w && op.scaled.Bounds().Dy() == h {
+		return op.scaled
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), op.Image, op.Image.Bounds(), draw.Src, nil)
+	return scaled
+}
+
+func (op *BlitOperation) WriteSVG(w io.Writer) error {
+	if op.Image == nil {
+		return nil
+	}
+	img := op.scaledImage()
+
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, `  <image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`+"\n",
+		int(op.X), int(op.Y), img.Bounds().Dx(), img.Bounds().Dy(), base64.StdEncoding.EncodeToString(data.Bytes()))
+	return err
+}
+
+// drawImage переносить зображення на текстуру так, щоб його лівий верхній кут опинився у точці dp.
+// Непрозорі зображення завантажуються через screen.Buffer, якщо доступний s. Зображення з прозорими пікселями, як
+// і всі зображення без s, малюються відрізками через Fill, щоб прозорість змішувалась з уже намальованим вмістом.
+func drawImage(s screen.Screen, t screen.Texture, dp image.Point, img *image.RGBA) {
+	dst := img.Bounds().Add(dp).Intersect(t.Bounds())
+	if dst.Empty() {
+		return
+	}
+	sr := dst.Sub(dp)
+
+	if s != nil && img.SubImage(sr).(*image.RGBA).Opaque() {
+		if buf, err := s.NewBuffer(sr.Size()); err == nil {
+			defer buf.Release()
+			draw.Draw(buf.RGBA(), buf.Bounds(), img, sr.Min, draw.Src)
+			t.Upload(dst.Min, buf, buf.Bounds())
+			return
+		}
+	}
+
+	fillPixels(t, img, sr, dp, screen.Over)
+}
+
+// fillPixels малює пікселі області r зображення img, зміщені на dp, об'єднуючи сусідні пікселі однакового кольору
+// в один виклик Fill. У режимі Over напівпрозорі пікселі змішуються з уже намальованим вмістом.
+func fillPixels(t screen.Texture, img *image.RGBA, r image.Rectangle, dp image.Point, blend draw.Op) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; {
+			c := img.RGBAAt(x, y)
+			start := x
+			for x < r.Max.X && img.RGBAAt(x, y) == c {
+				x++
+			}
+			if c.A == 0 && blend != screen.Src {
+				continue
+			}
+
+			op := screen.Over
+			if c.A == 0xff || blend == screen.Src {
+				op = screen.Src
+			}
+			t.Fill(image.Rect(start, y, x, y+1).Add(dp), c, op)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+type imageBuffer struct {
+	img *image.RGBA
+}
+
+func (b *imageBuffer) Release()                {}
+func (b *imageBuffer) Size() image.Point       { return b.img.Bounds().Size() }
+func (b *imageBuffer) Bounds() image.Rectangle { return b.img.Bounds() }
+func (b *imageBuffer) RGBA() *image.RGBA       { return b.img }
+
+// bufferScreen екран, який уміє створювати буфери у пам'яті
+type bufferScreen struct {
+	mockScreen
+}
+
+func (bufferScreen) NewBuffer(size image.Point) (screen.Buffer, error) {
+	return &imageBuffer{img: image.NewRGBA(image.Rectangle{Max: size})}, nil
+}
+
+func checkerboard(c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, c)
+	img.SetRGBA(1, 1, c)
+	img.SetRGBA(1, 0, color.RGBA{A: 0xff})
+	img.SetRGBA(0, 1, color.RGBA{A: 0xff})
+	return img
+}
+
+func TestBlitOperation_Upload(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	tx := newImageTexture()
+	op := NewBlitOperation(checkerboard(red), 10, 20, 20, 20)
+
+	var l Loop
+	l.screen = bufferScreen{}
+	l.next = &frame{texture: tx, refs: 1}
+	l.do(op)
+
+	if tx.uploads != 1 {
+		t.Errorf("Expected opaque image to be uploaded once, got %d uploads", tx.uploads)
+	}
+	if tx.img.RGBAAt(12, 22) != red || tx.img.RGBAAt(27, 37) != red {
+		t.Error("Expected scaled image to be drawn at (10, 20)")
+	}
+	if tx.isSet(9, 20) || tx.isSet(30, 40) {
+		t.Error("Expected nothing to be drawn outside the image")
+	}
+}
+
+func TestBlitOperation_TransparentUsesFill(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	img.SetRGBA(1, 0, color.RGBA{G: 0xff, A: 0xff})
+
+	tx := newImageTexture()
+	tx.Fill(tx.Bounds(), color.White, screen.Src)
+	NewBlitOperation(img, 798, 0, 0, 0).DoScreen(bufferScreen{}, tx)
+
+	if tx.uploads != 0 {
+		t.Error("Expected transparent image not to be uploaded")
+	}
+	if tx.img.RGBAAt(798, 0) != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Error("Expected transparent pixel to keep the background")
+	}
+	if tx.img.RGBAAt(799, 0) != (color.RGBA{G: 0xff, A: 0xff}) {
+		t.Error("Expected opaque pixel to be drawn")
+	}
+}
+
+func TestBlitOperation_WriteSVG(t *testing.T) {
+	var out strings.Builder
+	if err := NewBlitOperation(checkerboard(color.RGBA{A: 0xff}), 5, 6, 0, 0).WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(out.String(), `<image x="5" y="6" width="2" height="2" href="data:image/png;base64,`) {
+		t.Errorf("Unexpected SVG output: %s", out.String())
+	}
+}
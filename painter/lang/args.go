@@ -0,0 +1,78 @@
+package lang
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// commandParams імена позиційних аргументів команд, які можна передавати за іменем: circle x=0.5 y=0.5 r=0.1
+var commandParams = map[string][]string{
+	"bgrect":  {"x1", "y1", "x2", "y2"},
+	"figure":  {"x", "y"},
+	"move":    {"x", "y"},
+	"circle":  {"x", "y", "r"},
+	"ellipse": {"x", "y", "rx", "ry"},
+	"line":    {"x1", "y1", "x2", "y2", "width"},
+	"text":    {"x", "y", "size", "text"},
+	"blit":    {"name", "x", "y", "w", "h"},
+	"animate": {"target", "x", "y", "duration", "easing"},
+	"rotate":  {"target", "deg"},
+	"scale":   {"target", "k"},
+}
+
+// namedArguments перетворює іменовані аргументи команди на позиційні. Позиційні аргументи мають іти перед
+// іменованими; поля після першого іменованого аргументу, як і параметри на зразок rot=45, залишаються
+// наприкінці команди. Пропускати можна лише останні аргументи.
+func namedArguments(fields []field) ([]field, error) {
+	params, ok := commandParams[fields[0].text]
+	if !ok || fields[0].quoted {
+		return fields, nil
+	}
+
+	positional := fields[:1:1]
+	var rest []field
+	named := make(map[string]field)
+	for _, f := range fields[1:] {
+		name, value, isNamed := strings.Cut(f.text, "=")
+		if isNamed && slices.Contains(params, name) {
+			if _, dup := named[name]; dup {
+				return nil, fmt.Errorf("duplicate argument %s", name)
+			}
+			named[name] = field{text: value, quoted: f.quoted, col: f.col + len(name) + 1}
+		} else if len(named) == 0 && !isNamed {
+			positional = append(positional, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	if len(named) == 0 {
+		return fields, nil
+	}
+
+	// Ім'я фігури у команді figure не має власного параметра та йде перед координатами
+	given := len(positional) - 1
+	if fields[0].text == "figure" && given > 0 && isFigureName(positional[1].text) {
+		given--
+	}
+
+	res := positional
+	missing := ""
+	for i, param := range params {
+		value, ok := named[param]
+		switch {
+		case i < given:
+			if ok {
+				return nil, fmt.Errorf("argument %s is already given as positional", param)
+			}
+		case ok:
+			if missing != "" {
+				return nil, fmt.Errorf("missing argument %s", missing)
+			}
+			res = append(res, value)
+		case missing == "":
+			missing = param
+		}
+	}
+	return append(res, rest...), nil
+}
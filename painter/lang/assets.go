@@ -0,0 +1,158 @@
+package lang
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// maxAssetSize максимальний розмір файлу зображення, який можна завантажити
+const maxAssetSize = 16 << 20
+
+// maxAssetPixels максимальна кількість пікселів зображення. Стиснений файл може бути маленьким, а розпаковане
+// зображення - займати гігабайти, тому розмір перевіряється за заголовком до розпакування.
+const maxAssetPixels = maxCanvasSize * maxCanvasSize
+
+// Обмеження сховища зображень за замовчуванням. Зображення зберігаються розпакованими, тому загальний розмір
+// рахується в пікселях: 64 мільйони пікселів RGBA займають 256 МБ.
+const (
+	DefaultMaxAssets      = 64
+	DefaultMaxAssetPixels = 64 << 20
+)
+
+var (
+	errTooManyAssets  = errors.New("too many assets")
+	errAssetsTooLarge = errors.New("assets are too large")
+)
+
+var assetName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Assets зберігає растрові зображення, завантажені клієнтами, за назвами. Нульове значення готове до використання.
+type Assets struct {
+	MaxAssets int   // Максимальна кількість зображень; 0 означає DefaultMaxAssets
+	MaxPixels int64 // Максимальна загальна кількість пікселів усіх зображень; 0 означає DefaultMaxAssetPixels
+
+	mu     sync.RWMutex
+	images map[string]image.Image
+	pixels int64 // Загальна кількість пікселів збережених зображень
+}
+
+// Put зберігає зображення під заданою назвою, замінюючи попереднє. Повертає помилку, якщо нове зображення
+// перевищить обмеження кількості або загального розміру зображень.
+func (a *Assets) Put(name string, img image.Image) error {
+	if !assetName.MatchString(name) {
+		return fmt.Errorf("invalid asset name: %q", name)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	maxAssets, maxPixels := a.MaxAssets, a.MaxPixels
+	if maxAssets == 0 {
+		maxAssets = DefaultMaxAssets
+	}
+	if maxPixels == 0 {
+		maxPixels = DefaultMaxAssetPixels
+	}
+
+	pixels := a.pixels + imagePixels(img)
+	old, replaced := a.images[name]
+	if replaced {
+		pixels -= imagePixels(old)
+	} else if len(a.images) >= maxAssets {
+		return fmt.Errorf("%w: limit is %d", errTooManyAssets, maxAssets)
+	}
+	if pixels > maxPixels {
+		return fmt.Errorf("%w: limit is %d pixels in total", errAssetsTooLarge, maxPixels)
+	}
+
+	if a.images == nil {
+		a.images = make(map[string]image.Image)
+	}
+	a.images[name] = img
+	a.pixels = pixels
+	return nil
+}
+
+func imagePixels(img image.Image) int64 {
+	size := img.Bounds().Size()
+	return int64(size.X) * int64(size.Y)
+}
+
+// Get повертає зображення за назвою.
+func (a *Assets) Get(name string) (image.Image, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	img, ok := a.images[name]
+	return img, ok
+}
+
+// Delete видаляє зображення за назвою.
+func (a *Assets) Delete(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if img, ok := a.images[name]; ok {
+		a.pixels -= imagePixels(img)
+		delete(a.images, name)
+	}
+}
+
+// Decode розпізнає зображення у форматі PNG або JPEG та зберігає його під заданою назвою.
+func (a *Assets) Decode(name string, in io.Reader) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid image: %v", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxAssetPixels {
+		return fmt.Errorf("image %dx%d is too large: limit is %d pixels", cfg.Width, cfg.Height, maxAssetPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid image: %v", err)
+	}
+	return a.Put(name, img)
+}
+
+// AssetsHandler конструює обробник HTTP запитів для маршруту /assets/{name}: PUT або POST завантажує зображення у
+// форматі PNG чи JPEG, DELETE видаляє його. Якщо сховище заповнене, завантаження відхиляється з кодом 409 для
+// кількості зображень та 413 для їх загального розміру.
+func AssetsHandler(a *Assets) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		switch r.Method {
+		case http.MethodPut, http.MethodPost:
+			if err := a.Decode(name, http.MaxBytesReader(rw, r.Body, maxAssetSize)); err != nil {
+				switch {
+				case errors.Is(err, errTooManyAssets):
+					http.Error(rw, err.Error(), http.StatusConflict)
+				case errors.Is(err, errAssetsTooLarge):
+					http.Error(rw, err.Error(), http.StatusRequestEntityTooLarge)
+				default:
+					log.Printf("Bad asset %s: %s", name, err)
+					rw.WriteHeader(http.StatusBadRequest)
+				}
+				return
+			}
+			rw.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			a.Delete(name)
+			rw.WriteHeader(http.StatusNoContent)
+		default:
+			rw.Header().Set("Allow", "PUT, POST, DELETE")
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package lang
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+func encodePNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.White)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// hugePNG повертає заголовок PNG, який оголошує зображення 50000×50000 без даних пікселів
+func hugePNG() []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 50000)
+	binary.BigEndian.PutUint32(ihdr[4:], 50000)
+	ihdr[8], ihdr[9] = 8, 6 // 8 біт на канал, RGBA
+
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf := bytes.NewBufferString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestAssets_DecodeTooLarge(t *testing.T) {
+	var assets Assets
+	err := assets.Decode("huge", bytes.NewReader(hugePNG()))
+	if err == nil || !strings.Contains(err.Error(), "50000x50000 is too large") {
+		t.Errorf("Expected image size error, got %v", err)
+	}
+	if _, ok := assets.Get("huge"); ok {
+		t.Error("Expected huge image not to be stored")
+	}
+}
+
+func TestAssetsHandler(t *testing.T) {
+	var assets Assets
+	mux := http.NewServeMux()
+	mux.Handle("/assets/{name}", AssetsHandler(&assets))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/assets/logo", bytes.NewReader(encodePNG(t))))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 after upload, got %d", rec.Code)
+	}
+	if img, ok := assets.Get("logo"); !ok || img.Bounds().Dx() != 4 {
+		t.Errorf("Expected uploaded image to be stored, got %v", img)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/assets/bad", strings.NewReader("not an image")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid image, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/assets/logo", nil))
+	if _, ok := assets.Get("logo"); ok || rec.Code != http.StatusNoContent {
+		t.Errorf("Expected image to be deleted, got status %d", rec.Code)
+	}
+}
+
+func TestAssetsHandler_Limits(t *testing.T) {
+	assets := &Assets{MaxAssets: 2, MaxPixels: 20} // Зображення encodePNG має 8 пікселів
+	mux := http.NewServeMux()
+	mux.Handle("/assets/{name}", AssetsHandler(assets))
+	put := func(name string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/assets/"+name, bytes.NewReader(encodePNG(t))))
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		name string
+		code int
+	}{
+		{"a", http.StatusCreated},
+		{"b", http.StatusCreated},
+		{"c", http.StatusConflict},
+		{"a", http.StatusCreated}, // Заміна не збільшує кількість зображень
+	} {
+		if code := put(tc.name); code != tc.code {
+			t.Errorf("Upload %s: expected %d, got %d", tc.name, tc.code, code)
+		}
+	}
+
+	assets.MaxAssets = 3
+	if code := put("c"); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 when the total size is exceeded, got %d", code)
+	}
+	assets.Delete("b")
+	if code := put("c"); code != http.StatusCreated {
+		t.Errorf("Expected deleted image to free space, got %d", code)
+	}
+}
+
+func TestParser_ParseBlit(t *testing.T) {
+	var assets Assets
+	if err := assets.Decode("logo", bytes.NewReader(encodePNG(t))); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	parser := &Parser{Assets: &assets}
+
+	ops, err := parser.Parse(strings.NewReader("blit logo 0.1 0.2 0.5 0.25"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	blit, ok := ops[0].(*painter.BlitOperation)
+	if !ok {
+		t.Fatalf("Expected BlitOperation, got %T", ops[0])
+	}
+	if blit.X != scale(0.1) || blit.W != scale(0.5) || blit.H != scale(0.25) {
+		t.Errorf("BlitOperation has incorrect values: %+v", blit)
+	}
+
+	if _, err := parser.Parse(strings.NewReader("blit missing 0.1 0.1")); err == nil || !strings.Contains(err.Error(), "unknown asset") {
+		t.Errorf("Expected unknown asset error, got %v", err)
+	}
+	if err := assets.Put("../etc", nil); err == nil {
+		t.Error("Expected invalid asset name error")
+	}
+}
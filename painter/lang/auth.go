@@ -0,0 +1,213 @@
+package lang
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope дозволи токена доступу.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"  // Перегляд сцени: знімки, потоки кадрів та подій
+	ScopeWrite Scope = "write" // Виконання команд та зміна стану: скрипти, зображення, полотна, записи
+)
+
+// Token токен доступу клієнта.
+type Token struct {
+	Name   string  // Назва клієнта для журналів
+	Scopes []Scope // Дозволи токена
+	Rate   float64 // Середня кількість запитів за секунду; 0 означає без обмежень
+	Burst  int     // Кількість запитів, які можна зробити поспіль; 0 означає max(1, Rate)
+}
+
+func (t *Token) allows(scope Scope) bool {
+	for _, s := range t.Scopes {
+		// Запис включає перегляд, бо клієнт, який змінює сцену, має бачити результат
+		if s == scope || s == ScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// Auth перевіряє токени доступу з заголовка Authorization: Bearer <token>. Клієнти, які не можуть задати заголовок,
+// як WebSocket та EventSource у браузері, передають токен у параметрі access_token.
+type Auth struct {
+	tokens map[[sha256.Size]byte]*tokenState
+	now    func() time.Time
+}
+
+// tokenState токен разом з його відром запитів для обмеження частоти
+type tokenState struct {
+	Token
+	mu     sync.Mutex
+	tokens float64 // Кількість запитів, які можна зробити зараз
+	last   time.Time
+}
+
+// NewAuth створює перевірку для токенів, заданих значеннями секретів.
+func NewAuth(tokens map[string]Token) *Auth {
+	a := &Auth{tokens: make(map[[sha256.Size]byte]*tokenState), now: time.Now}
+	for secret, t := range tokens {
+		// Зберігаються лише хеші, тому пошук токена не залежить від того, скільки символів секрету збіглося
+		a.tokens[sha256.Sum256([]byte(secret))] = &tokenState{Token: t, tokens: float64(t.burst())}
+	}
+	return a
+}
+
+func (t *Token) burst() int {
+	if t.Burst > 0 {
+		return t.Burst
+	}
+	return max(1, int(math.Ceil(t.Rate)))
+}
+
+// LoadAuth зчитує токени з файлу. Кожен рядок описує один токен:
+//
+//	<secret> <name> <read|write|read,write> [rate=<n>/s] [burst=<n>]
+//
+// Порожні рядки та рядки, що починаються з #, пропускаються.
+func LoadAuth(path string) (*Auth, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadAuth(f)
+}
+
+// ReadAuth зчитує токени у форматі LoadAuth з r.
+func ReadAuth(r io.Reader) (*Auth, error) {
+	tokens := make(map[string]Token)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		secret, t, err := parseToken(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, ok := tokens[secret]; ok {
+			return nil, fmt.Errorf("line %d: duplicate token for %s", line, t.Name)
+		}
+		tokens[secret] = t
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewAuth(tokens), nil
+}
+
+func parseToken(fields []string) (string, Token, error) {
+	if len(fields) < 3 {
+		return "", Token{}, fmt.Errorf("expected <secret> <name> <scopes> [rate=<n>/s] [burst=<n>]")
+	}
+	t := Token{Name: fields[1]}
+	for _, s := range strings.Split(fields[2], ",") {
+		switch scope := Scope(s); scope {
+		case ScopeRead, ScopeWrite:
+			t.Scopes = append(t.Scopes, scope)
+		default:
+			return "", Token{}, fmt.Errorf("unknown scope %q: expected read or write", s)
+		}
+	}
+
+	for _, option := range fields[3:] {
+		name, value, _ := strings.Cut(option, "=")
+		switch name {
+		case "rate":
+			rate, err := strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+			if err != nil || rate < 0 {
+				return "", Token{}, fmt.Errorf("invalid rate %q: expected requests per second like 5/s", value)
+			}
+			t.Rate = rate
+		case "burst":
+			burst, err := strconv.Atoi(value)
+			if err != nil || burst < 1 {
+				return "", Token{}, fmt.Errorf("invalid burst %q: expected a positive number", value)
+			}
+			t.Burst = burst
+		default:
+			return "", Token{}, fmt.Errorf("unknown option %q", option)
+		}
+	}
+	return fields[0], t, nil
+}
+
+// Protect пропускає до h лише запити з токеном, який має дозвіл scope і не перевищив свою частоту запитів. Інакше
+// відповідає 401 без дійсного токена, 403 без потрібного дозволу та 429 з заголовком Retry-After. Якщо a nil,
+// перевірка вимкнена і h повертається без змін.
+func (a *Auth) Protect(scope Scope, h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t, ok := a.tokens[sha256.Sum256([]byte(bearerToken(r)))]
+		switch {
+		case !ok:
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="painter"`)
+			http.Error(rw, "missing or invalid access token", http.StatusUnauthorized)
+		case !t.allows(scope):
+			rw.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="painter", error="insufficient_scope", scope="%s"`, scope))
+			http.Error(rw, fmt.Sprintf("token %s has no %s access", t.Name, scope), http.StatusForbidden)
+		default:
+			if wait := t.take(a.now()); wait > 0 {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(rw, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			h.ServeHTTP(rw, r)
+		}
+	})
+}
+
+// ProtectMethods захищає h дозволом read для запитів GET та HEAD і дозволом write для решти.
+func (a *Auth) ProtectMethods(h http.Handler) http.Handler {
+	read, write := a.Protect(ScopeRead, h), a.Protect(ScopeWrite, h)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			read.ServeHTTP(rw, r)
+		} else {
+			write.ServeHTTP(rw, r)
+		}
+	})
+}
+
+// bearerToken повертає токен із заголовка Authorization або параметра access_token
+func bearerToken(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// take забирає один запит з відра токена та повертає 0 або час, через який запит стане доступним
+func (t *tokenState) take(now time.Time) time.Duration {
+	if t.Rate == 0 {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.last.IsZero() {
+		t.tokens = min(float64(t.burst()), t.tokens+now.Sub(t.last).Seconds()*t.Rate)
+	}
+	t.last = now
+	if t.tokens < 1 {
+		return time.Duration((1 - t.tokens) / t.Rate * float64(time.Second))
+	}
+	t.tokens--
+	return 0
+}
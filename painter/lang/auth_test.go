@@ -0,0 +1,85 @@
+package lang
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadAuth(t *testing.T) {
+	_, err := ReadAuth(strings.NewReader("# comment\n\nabc viewer read\nabc ci write\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 4: duplicate token") {
+		t.Errorf("Expected duplicate token error, got %v", err)
+	}
+	for _, line := range []string{"abc viewer", "abc viewer admin", "abc viewer read rate=fast", "abc viewer read burst=0", "abc viewer read x=1"} {
+		if _, err := ReadAuth(strings.NewReader(line)); err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+			t.Errorf("Expected error for %q, got %v", line, err)
+		}
+	}
+}
+
+func TestAuth_Protect(t *testing.T) {
+	auth, err := ReadAuth(strings.NewReader(`
+viewer-secret  dashboard  read
+writer-secret  ci         read,write  rate=1/s  burst=2
+`))
+	if err != nil {
+		t.Fatalf("ReadAuth error: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	auth.now = func() time.Time { return now }
+
+	ok := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+	write := auth.Protect(ScopeWrite, ok)
+	read := auth.Protect(ScopeRead, ok)
+	request := func(h http.Handler, target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request(write, "/", ""); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with challenge, got %d", rec.Code)
+	}
+	if rec := request(write, "/", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for unknown token, got %d", rec.Code)
+	}
+	if rec := request(write, "/", "viewer-secret"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for read-only token, got %d", rec.Code)
+	}
+	if rec := request(read, "/", "viewer-secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected read access, got %d", rec.Code)
+	}
+	if rec := request(read, "/?access_token=writer-secret", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected write token to allow reading via query, got %d", rec.Code)
+	}
+
+	// Один запит вже використано, тому з відра на два запити лишився один
+	if rec := request(write, "/", "writer-secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected request within burst, got %d", rec.Code)
+	}
+	rec := request(write, "/", "writer-secret")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 429 with Retry-After 1, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	now = now.Add(time.Second)
+	if rec := request(write, "/", "writer-secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected request after refill, got %d", rec.Code)
+	}
+}
+
+func TestAuth_Disabled(t *testing.T) {
+	var auth *Auth
+	rec := httptest.NewRecorder()
+	auth.ProtectMethods(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected requests to pass without auth, got %d", rec.Code)
+	}
+}
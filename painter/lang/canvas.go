@@ -0,0 +1,343 @@
+package lang
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Межі розміру полотна у пікселях
+const (
+	minCanvasSize = 16
+	maxCanvasSize = 4096
+)
+
+// DefaultMaxCanvases кількість полотен разом з головним, якщо Canvases.MaxCanvases не задано. Кожне полотно
+// тримає щонайменше дві текстури, тобто до 128 МБ для найбільшого розміру.
+const DefaultMaxCanvases = 8
+
+// canvasName допустимі назви полотен
+var canvasName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Помилки керування полотнами
+var (
+	errCanvasExists   = errors.New("canvas already exists")
+	errCanvasNotFound = errors.New("canvas not found")
+	errMainCanvas     = errors.New("the main canvas cannot be deleted")
+	errScreenNotReady = errors.New("screen is not ready yet")
+	errTooMany        = errors.New("too many canvases")
+)
+
+// Canvas полотно з власним циклом подій та станом парсера.
+type Canvas struct {
+	Name    string
+	Loop    *painter.Loop
+	Parser  *Parser
+	Handler *Handler // Обробник скриптів полотна; якщо не заданий, Add створює його з Loop та Parser
+}
+
+// CanvasInfo опис полотна у відповідях API.
+type CanvasInfo struct {
+	Name  string `json:"name"`
+	Size  int    `json:"size"`
+	Shown bool   `json:"shown"` // Полотно зараз показується у вікні
+}
+
+// Canvases керує іменованими полотнами. Перше додане полотно головне: його не можна видалити, і на нього
+// перемикається вікно, якщо показане полотно видаляють. Запити обслуговуються за адресами:
+//
+//	GET    /canvas                   перелік полотен
+//	PUT    /canvas/{name}?size=<px>  створити полотно; розмір за замовчуванням DefaultCanvasSize
+//	GET    /canvas/{name}            опис полотна
+//	POST   /canvas/{name}            виконати скрипт на полотні
+//	DELETE /canvas/{name}            зупинити цикл полотна та видалити його
+//	POST   /canvas/{name}/show       показати полотно у вікні
+//	GET    /canvas/{name}/svg        сцена полотна у форматі SVG
+type Canvases struct {
+	Display painter.Receiver // Отримувач кадрів полотна, яке зараз показується, наприклад вікно
+	Assets  *Assets          // Зображення для команди blit на нових полотнах
+	Scripts fs.FS            // Каталог скриптів для команди include на нових полотнах
+
+	MaxCanvases int   // Максимальна кількість полотен разом з головним; 0 означає DefaultMaxCanvases
+	MaxLines    int   // Обмеження кількості рядків скрипта на нових полотнах (див. Parser.MaxLines)
+	MaxBodySize int64 // Обмеження розміру тіла запиту для обробників, які створює Add (див. Handler.MaxBodySize)
+
+	mu       sync.Mutex
+	screen   screen.Screen
+	canvases map[string]*Canvas
+	main     *Canvas
+	shown    *Canvas
+
+	routes     *http.ServeMux
+	routesOnce sync.Once
+}
+
+// Add реєструє полотно з уже налаштованими циклом та парсером. Цикл запускається під час Start, а якщо екран вже
+// готовий - одразу.
+func (cs *Canvases) Add(c *Canvas) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if _, ok := cs.canvases[c.Name]; ok {
+		return fmt.Errorf("%w: %s", errCanvasExists, c.Name)
+	}
+	limit := cs.MaxCanvases
+	if limit == 0 {
+		limit = DefaultMaxCanvases
+	}
+	if len(cs.canvases) >= limit {
+		return fmt.Errorf("%w: limit is %d", errTooMany, limit)
+	}
+	if cs.canvases == nil {
+		cs.canvases = make(map[string]*Canvas)
+	}
+	if c.Handler == nil {
+		c.Handler = &Handler{Loop: c.Loop, Parser: c.Parser, MaxBodySize: cs.MaxBodySize}
+	}
+	cs.canvases[c.Name] = c
+	if cs.main == nil {
+		cs.main = c
+		cs.show(c)
+	}
+	if cs.screen != nil {
+		c.Loop.Start(cs.screen)
+	}
+	return nil
+}
+
+// Start запускає цикли всіх зареєстрованих полотен на екрані s.
+func (cs *Canvases) Start(s screen.Screen) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.screen = s
+	for _, c := range cs.canvases {
+		c.Loop.Start(s)
+	}
+}
+
+// Create створює полотно розміром size×size пікселів з власними циклом та парсером.
+func (cs *Canvases) Create(name string, size int) (*Canvas, error) {
+	if !canvasName.MatchString(name) {
+		return nil, fmt.Errorf("invalid canvas name %q: expected letters, digits, _ or -", name)
+	}
+	if size < minCanvasSize || size > maxCanvasSize {
+		return nil, fmt.Errorf("canvas size %d out of range [%d - %d]", size, minCanvasSize, maxCanvasSize)
+	}
+	cs.mu.Lock()
+	ready := cs.screen != nil
+	cs.mu.Unlock()
+	if !ready {
+		return nil, errScreenNotReady
+	}
+
+	c := &Canvas{
+		Name:   name,
+		Loop:   &painter.Loop{Size: image.Pt(size, size)},
+		Parser: &Parser{Assets: cs.Assets, Scripts: cs.Scripts, Size: size, MaxLines: cs.MaxLines},
+	}
+	if err := cs.Add(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Delete зупиняє цикл полотна та видаляє його. Якщо полотно показувалось, вікно перемикається на головне.
+func (cs *Canvases) Delete(name string) error {
+	cs.mu.Lock()
+	c, ok := cs.canvases[name]
+	switch {
+	case !ok:
+		cs.mu.Unlock()
+		return fmt.Errorf("%w: %s", errCanvasNotFound, name)
+	case c == cs.main:
+		cs.mu.Unlock()
+		return errMainCanvas
+	}
+	delete(cs.canvases, name)
+	if cs.shown == c {
+		cs.show(cs.main)
+	}
+	cs.mu.Unlock()
+
+	c.Loop.StopAndWait()
+	return nil
+}
+
+// Show перемикає вікно на полотно name.
+func (cs *Canvases) Show(name string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	c, ok := cs.canvases[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errCanvasNotFound, name)
+	}
+	cs.show(c)
+	return nil
+}
+
+// ShowNext перемикає вікно на наступне за назвою полотно.
+func (cs *Canvases) ShowNext() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	names := cs.names()
+	if len(names) == 0 {
+		return
+	}
+	i := sort.SearchStrings(names, cs.shown.Name)
+	cs.show(cs.canvases[names[(i+1)%len(names)]])
+}
+
+// show переносить Display на полотно c та перемальовує його сцену, бо цикл полотна міг не надсилати кадрів
+func (cs *Canvases) show(c *Canvas) {
+	if cs.shown == c {
+		return
+	}
+	if cs.shown != nil && cs.Display != nil {
+		cs.shown.Loop.RemoveReceiver(cs.Display)
+	}
+	cs.shown = c
+	if cs.Display != nil {
+		c.Loop.AddReceiver(cs.Display)
+		c.Loop.Post(c.Parser.frame())
+	}
+}
+
+// Get повертає полотно за назвою.
+func (cs *Canvases) Get(name string) (*Canvas, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	c, ok := cs.canvases[name]
+	return c, ok
+}
+
+// List повертає описи всіх полотен, впорядковані за назвою.
+func (cs *Canvases) List() []CanvasInfo {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	res := []CanvasInfo{}
+	for _, name := range cs.names() {
+		res = append(res, cs.info(cs.canvases[name]))
+	}
+	return res
+}
+
+func (cs *Canvases) names() []string {
+	var names []string
+	for name := range cs.canvases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (cs *Canvases) info(c *Canvas) CanvasInfo {
+	return CanvasInfo{Name: c.Name, Size: c.Parser.canvasSize(), Shown: c == cs.shown}
+}
+
+func (cs *Canvases) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	cs.routesOnce.Do(func() {
+		cs.routes = http.NewServeMux()
+		cs.routes.HandleFunc("GET /canvas", func(rw http.ResponseWriter, r *http.Request) {
+			writeJSON(rw, http.StatusOK, cs.List())
+		})
+		cs.routes.HandleFunc("PUT /canvas/{name}", cs.serveCreate)
+		cs.routes.HandleFunc("GET /canvas/{name}", cs.withCanvas(func(rw http.ResponseWriter, r *http.Request, c *Canvas) {
+			cs.mu.Lock()
+			info := cs.info(c)
+			cs.mu.Unlock()
+			writeJSON(rw, http.StatusOK, info)
+		}))
+		cs.routes.HandleFunc("POST /canvas/{name}", cs.withCanvas(func(rw http.ResponseWriter, r *http.Request, c *Canvas) {
+			c.Handler.ServeHTTP(rw, r)
+		}))
+		cs.routes.HandleFunc("DELETE /canvas/{name}", func(rw http.ResponseWriter, r *http.Request) {
+			if err := cs.Delete(r.PathValue("name")); err != nil {
+				canvasError(rw, err)
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		})
+		cs.routes.HandleFunc("POST /canvas/{name}/show", func(rw http.ResponseWriter, r *http.Request) {
+			if err := cs.Show(r.PathValue("name")); err != nil {
+				canvasError(rw, err)
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		})
+		cs.routes.HandleFunc("GET /canvas/{name}/svg", cs.withCanvas(func(rw http.ResponseWriter, r *http.Request, c *Canvas) {
+			SVGHandler(c.Loop, c.Parser).ServeHTTP(rw, r)
+		}))
+	})
+	cs.routes.ServeHTTP(rw, r)
+}
+
+func (cs *Canvases) serveCreate(rw http.ResponseWriter, r *http.Request) {
+	size := DefaultCanvasSize
+	if raw := r.URL.Query().Get("size"); raw != "" {
+		var err error
+		if size, err = strconv.Atoi(raw); err != nil {
+			http.Error(rw, fmt.Sprintf("invalid canvas size %q", raw), http.StatusBadRequest)
+			return
+		}
+	}
+
+	c, err := cs.Create(r.PathValue("name"), size)
+	if err != nil {
+		canvasError(rw, err)
+		return
+	}
+	cs.mu.Lock()
+	info := cs.info(c)
+	cs.mu.Unlock()
+	writeJSON(rw, http.StatusCreated, info)
+}
+
+// withCanvas знаходить полотно з адреси запиту або відповідає 404
+func (cs *Canvases) withCanvas(h func(rw http.ResponseWriter, r *http.Request, c *Canvas)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		c, ok := cs.Get(r.PathValue("name"))
+		if !ok {
+			canvasError(rw, fmt.Errorf("%w: %s", errCanvasNotFound, r.PathValue("name")))
+			return
+		}
+		h(rw, r, c)
+	}
+}
+
+// canvasError відповідає статусом, який відповідає помилці керування полотнами
+func canvasError(rw http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, errCanvasNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errCanvasExists), errors.Is(err, errMainCanvas), errors.Is(err, errTooMany):
+		status = http.StatusConflict
+	case errors.Is(err, errScreenNotReady):
+		status = http.StatusServiceUnavailable
+	}
+	http.Error(rw, err.Error(), status)
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Printf("Failed to write response: %s", err)
+	}
+}
@@ -0,0 +1,121 @@
+package lang
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// sizeScreen створює текстури, які лише запам'ятовують свій розмір
+type sizeScreen struct{}
+
+func (sizeScreen) NewBuffer(image.Point) (screen.Buffer, error) { return nil, nil }
+func (sizeScreen) NewTexture(size image.Point) (screen.Texture, error) {
+	return sizeTexture{size}, nil
+}
+func (sizeScreen) NewWindow(*screen.NewWindowOptions) (screen.Window, error) { return nil, nil }
+
+type sizeTexture struct{ size image.Point }
+
+func (t sizeTexture) Release()                                           {}
+func (t sizeTexture) Size() image.Point                                  { return t.size }
+func (t sizeTexture) Bounds() image.Rectangle                            { return image.Rectangle{Max: t.size} }
+func (t sizeTexture) Upload(image.Point, screen.Buffer, image.Rectangle) {}
+func (t sizeTexture) Fill(image.Rectangle, color.Color, draw.Op)         {}
+
+// sizeReceiver передає розміри отриманих кадрів у канал
+type sizeReceiver chan image.Point
+
+func (r sizeReceiver) Update(t screen.Texture) { r <- t.Size() }
+
+func (r sizeReceiver) next(t *testing.T) image.Point {
+	t.Helper()
+	select {
+	case size := <-r:
+		return size
+	case <-time.After(time.Second):
+		t.Fatal("No frame was shown")
+		return image.Point{}
+	}
+}
+
+func TestCanvases(t *testing.T) {
+	display := make(sizeReceiver, 10)
+	cs := &Canvases{Display: display}
+	var main painter.Loop
+	if err := cs.Add(&Canvas{Name: "main", Loop: &main, Parser: &Parser{}}); err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		cs.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := request(http.MethodPut, "/canvas/small", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the screen is ready, got %d", rec.Code)
+	}
+	cs.Start(sizeScreen{})
+	defer main.StopAndWait()
+
+	if rec := request(http.MethodPut, "/canvas/small?size=100", ""); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := request(http.MethodPut, "/canvas/small", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for existing canvas, got %d", rec.Code)
+	}
+	if rec := request(http.MethodPut, "/canvas/huge?size=100000", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid size, got %d", rec.Code)
+	}
+	cs.MaxCanvases = 2
+	if rec := request(http.MethodPut, "/canvas/third", ""); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "limit is 2") {
+		t.Errorf("Expected 409 above the canvas limit, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := request(http.MethodPost, "/canvas/small", "white\nfigure 0.5 0.5\nupdate"); rec.Code != http.StatusOK {
+		t.Errorf("Expected script to run, got %d: %s", rec.Code, rec.Body)
+	}
+	small, _ := cs.Get("small")
+	if x := small.Parser.Scene().Operations[0].(painter.FigureOperation).X; x != 50 {
+		t.Errorf("Expected figure in the middle of 100px canvas, got x=%v", x)
+	}
+
+	// Вікно показує кадри лише того полотна, яке вибране
+	if rec := request(http.MethodPost, "/canvas/small/show", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if size := display.next(t); size != image.Pt(100, 100) {
+		t.Errorf("Expected small canvas frame, got %v", size)
+	}
+
+	var list []CanvasInfo
+	if err := json.NewDecoder(request(http.MethodGet, "/canvas", "").Body).Decode(&list); err != nil {
+		t.Fatalf("Invalid list: %v", err)
+	}
+	want := []CanvasInfo{{"main", DefaultCanvasSize, false}, {"small", 100, true}}
+	if len(list) != 2 || list[0] != want[0] || list[1] != want[1] {
+		t.Errorf("Unexpected canvases %v", list)
+	}
+
+	if rec := request(http.MethodDelete, "/canvas/main", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for main canvas, got %d", rec.Code)
+	}
+	if rec := request(http.MethodDelete, "/canvas/small", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+	if size := display.next(t); size != image.Pt(800, 800) {
+		t.Errorf("Expected window to switch back to the main canvas, got %v", size)
+	}
+	if rec := request(http.MethodGet, "/canvas/small", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for deleted canvas, got %d", rec.Code)
+	}
+}
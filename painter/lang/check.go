@@ -0,0 +1,161 @@
+package lang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CommandDoc описує команду мови для підказок у редакторах.
+type CommandDoc struct {
+	Name  string
+	Usage string
+	Doc   string
+}
+
+// Commands перелік команд та ключових слів мови.
+var Commands = []CommandDoc{
+	{"white", "white", "Fill the background with white."},
+	{"green", "green", "Fill the background with green."},
+	{"gradient", "gradient linear <#from> <#to> <angle> | gradient radial <#from> <#to> <cx> <cy> <r>",
+		"Fill the background with a gradient."},
+	{"update", "update", "Show the texture on the screen."},
+	{"reset", "reset", "Clear the scene and fill the background with black."},
+	{"bgrect", "bgrect <x1> <y1> <x2> <y2> [style]", "Draw the background rectangle."},
+	{"figure", "figure [name] <x> <y> [scale=<k>] [rot=<deg>] [style]",
+		"Draw a figure centered at (x, y). Without a name the built-in T-shape is drawn."},
+	{"move", "move <x> <y>", "Move all figures to (x, y)."},
+	{"rotate", "rotate <target> <deg>", "Rotate figures: target is a figure number or all."},
+	{"scale", "scale <target> <k>", "Scale figures: target is a figure number or all."},
+	{"animate", "animate <target> <x> <y> <duration> [linear|ease-in|ease-out|ease-in-out|bounce]",
+		"Move figures smoothly to (x, y). Duration is in seconds or like 300ms."},
+	{"circle", "circle <x> <y> <r> [style] [gradient ...]", "Draw a circle."},
+	{"ellipse", "ellipse <x> <y> <rx> <ry> [style] [gradient ...]", "Draw an ellipse."},
+	{"line", "line <x1> <y1> <x2> <y2> [width] [style] [gradient ...]", "Draw a line segment."},
+	{"polygon", "polygon <x1> <y1> <x2> <y2> <x3> <y3> ... [style] [gradient ...]", "Draw a polygon."},
+	{"text", `text <x> <y> <size> "<string>" [left|center|right] [style]`, "Draw text with its baseline at y."},
+	{"blit", "blit <name> <x> <y> [w h]", "Draw an uploaded image with its top left corner at (x, y)."},
+	{"define", "define <name> { rect|circle|ellipse|polygon ... }",
+		"Define a composite figure. Part coordinates are relative to the figure center."},
+	{"let", "let <name> = <expr>", "Set a variable, referenced as $name."},
+	{"repeat", "repeat <count> [<name>] { ... }", "Repeat the block; the optional variable counts from 0."},
+	{"if", "if <expr> { ... } [else { ... }]", "Run the block when the expression is not zero."},
+	{"macro", "macro <name>(<params>) { ... }", "Define a macro called as name(args)."},
+	{"include", "include <file>", "Run a script from the server script directory."},
+}
+
+// CommandParams повертає імена аргументів команди, які можна передавати за іменем.
+func CommandParams(command string) []string {
+	return commandParams[command]
+}
+
+// Check виконує скрипт та повертає всі його помилки та попередження, впорядковані за рядками. Для чисел поза межами полотна
+// помилка вказує на конкретне поле. Якщо Assets не задано, посилання на зображення не перевіряються, бо вони
+// завантажуються на сервер окремо. Check змінює стан парсера так само, як Parse, тому для перевірки варто
+// створювати окремий Parser.
+func (p *Parser) Check(in io.Reader) []*ScriptError {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return []*ScriptError{{Err: err}}
+	}
+
+	var res []*ScriptError
+	_, warnings, err := p.ParseWithWarnings(strings.NewReader(string(src)))
+	if err != nil {
+		res = scriptErrors(err)
+	}
+	if p.Assets == nil {
+		res = remove(res, func(e *ScriptError) bool { return errors.Is(e, errUnknownAsset) })
+	}
+
+	// Точні помилки діапазону замінюють помилки тих самих рядків, які виконання повідомляє для всього рядка
+	block, _ := readScript(strings.NewReader(string(src)))
+	ranges := checkRanges(block, nil)
+	lines := make(map[int]bool)
+	for _, e := range ranges {
+		lines[e.Line] = true
+	}
+	res = remove(res, func(e *ScriptError) bool { return lines[e.Line] && errors.Is(e, errOutOfRange) })
+
+	res = append(res, ranges...)
+	res = append(res, warnings...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Line < res[j].Line || (res[i].Line == res[j].Line && res[i].Column < res[j].Column)
+	})
+	return res
+}
+
+// scriptErrors повертає помилки верхнього рівня з об'єднаної помилки Parse
+func scriptErrors(err error) []*ScriptError {
+	if se, ok := err.(*ScriptError); ok {
+		return []*ScriptError{se}
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var res []*ScriptError
+		for _, e := range joined.Unwrap() {
+			res = append(res, scriptErrors(e)...)
+		}
+		return res
+	}
+	return []*ScriptError{{Err: err}}
+}
+
+func remove(errs []*ScriptError, drop func(e *ScriptError) bool) []*ScriptError {
+	res := errs[:0]
+	for _, e := range errs {
+		if !drop(e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// checkRanges перевіряє, що числові аргументи команд, задані без виразів, лежать у межах від 0 до 1.
+// Блоки define пропускаються, бо координати частин фігури можуть бути від'ємними.
+func checkRanges(block []*statement, res []*ScriptError) []*ScriptError {
+	for _, st := range block {
+		if st.keyword() == "define" {
+			continue
+		}
+		res = checkRanges(st.body, res)
+		res = checkRanges(st.elseBody, res)
+		if st.block || len(st.fields) == 0 {
+			continue
+		}
+
+		command := st.keyword()
+		args, err := namedArguments(st.fields)
+		if err != nil || commandParams[command] == nil {
+			continue
+		}
+		args = args[1:]
+		if command == "figure" && len(args) > 0 && isFigureName(args[0].text) {
+			args = args[1:]
+		}
+		for i, param := range commandParams[command] {
+			if i >= len(args) || !normalizedParam(param) || args[i].quoted {
+				continue
+			}
+			v, err := strconv.ParseFloat(args[i].text, 64)
+			if err == nil && (v < 0 || v > 1) {
+				res = append(res, &ScriptError{
+					Line: st.line, Column: args[i].col + 1, Length: len(args[i].text),
+					Err: fmt.Errorf("%s value %s %w", param, args[i].text, errOutOfRange),
+				})
+			}
+		}
+	}
+	return res
+}
+
+// normalizedParam перевіряє, чи аргумент задається у нормалізованих одиницях полотна
+func normalizedParam(param string) bool {
+	switch param {
+	case "name", "target", "duration", "easing", "text", "deg", "k":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParser_Check(t *testing.T) {
+	input := `bgrect 0.1 -0.2 0.3 1.4
+repeat 2 {
+  circle x=2 y=0.5 r=0.1
+}
+define d {
+  rect -0.5 -0.5 0.5 0.5
+}
+blit logo 0.1 0.1
+move 0.1`
+
+	errs := (&Parser{}).Check(strings.NewReader(input))
+	want := []struct {
+		line, column int
+		message      string
+	}{
+		{1, 12, "y1 value -0.2 out of range"},
+		{1, 21, "y2 value 1.4 out of range"},
+		{3, 12, "x value 2 out of range"},
+		{9, 0, "invalid move command format"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %v", len(want), errs)
+	}
+	for i, w := range want {
+		if e := errs[i]; e.Line != w.line || e.Column != w.column || !strings.Contains(e.Error(), w.message) {
+			t.Errorf("Error %d: expected %d:%d %q, got %v", i, w.line, w.column, w.message, e)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package lang
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Типи подій, які надсилаються клієнтам Events
+const (
+	EventFrame = "frame" // Цикл передав кадр у Receiver
+	EventScene = "scene" // Прийнято скрипт, який змінює сцену
+	EventError = "error" // Скрипт відхилено через помилки
+	EventReset = "reset" // Події після Last-Event-ID вже видалено з історії; клієнт має заново отримати стан
+)
+
+// eventHistory кількість останніх подій, які зберігаються для відновлення потоку після перепідключення. Події
+// frame в історію не потрапляють: вони надходять з кожним кадром і витісняли б події scene та error.
+const eventHistory = 256
+
+// eventKeepAlive період, з яким клієнтам надсилається коментар, щоб проксі не закривали неактивне з'єднання
+var eventKeepAlive = 15 * time.Second
+
+// Event подія у потоці Server-Sent Events.
+type Event struct {
+	ID   uint64
+	Type string
+	Data string // Дані події у форматі JSON
+}
+
+// Events розсилає події підключеним клієнтам у форматі Server-Sent Events. Кожна подія має зростаючий номер, тому
+// клієнт, який перепідключився із заголовком Last-Event-ID, отримує пропущені події з історії, крім подій frame.
+// Якщо частина пропущених подій вже видалена з історії, клієнт спочатку отримує подію reset. Нульове значення
+// готове до використання.
+type Events struct {
+	mu          sync.Mutex
+	lastID      uint64
+	evicted     uint64 // Номер останньої події, видаленої з історії
+	history     []Event
+	subscribers map[chan Event]struct{}
+}
+
+// Publish надсилає подію типу typ з даними data, закодованими у JSON, всім підключеним клієнтам. Метод не
+// блокується: клієнт, який не встигає читати події, від'єднується та може відновити потік з історії.
+func (e *Events) Publish(typ string, data any) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %s", typ, err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastID++
+	ev := Event{ID: e.lastID, Type: typ, Data: string(encoded)}
+	if typ != EventFrame {
+		e.history = append(e.history, ev)
+		if len(e.history) > eventHistory {
+			e.evicted = e.history[len(e.history)-eventHistory-1].ID
+			e.history = e.history[len(e.history)-eventHistory:]
+		}
+	}
+
+	for ch := range e.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe підключає клієнта та повертає події з історії після події з номером after; 0 означає нового клієнта.
+// Якщо after старіший за історію або новіший за останню подію, наприклад після перезапуску сервера, першою
+// повертається подія reset.
+func (e *Events) subscribe(after uint64) (chan Event, []Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var missed []Event
+	if after > 0 && (after < e.evicted || after > e.lastID) {
+		missed = append(missed, Event{ID: e.lastID, Type: EventReset, Data: "{}"})
+	}
+	for _, ev := range e.history {
+		if ev.ID > after {
+			missed = append(missed, ev)
+		}
+	}
+
+	ch := make(chan Event, eventHistory)
+	if e.subscribers == nil {
+		e.subscribers = make(map[chan Event]struct{})
+	}
+	e.subscribers[ch] = struct{}{}
+	return ch, missed
+}
+
+func (e *Events) unsubscribe(ch chan Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.subscribers[ch]; ok {
+		delete(e.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (e *Events) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Браузери передають номер останньої події у заголовку, а параметр запиту зручний для інших клієнтів
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	var after uint64
+	if lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("invalid event id %q", lastID), http.StatusBadRequest)
+			return
+		}
+		after = id
+	}
+
+	ch, missed := e.subscribe(after)
+	defer e.unsubscribe(ch)
+
+	// Потік подій триває довше за ReadTimeout та WriteTimeout сервера, тому обмеження часу для нього знімаються:
+	// інакше після ReadTimeout сервер скасує контекст запиту і завершить потік
+	rc := http.NewResponseController(rw)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	for _, ev := range missed {
+		writeEvent(rw, ev)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return // Клієнт не встигав читати події
+			}
+			writeEvent(rw, ev)
+		case <-keepAlive.C:
+			fmt.Fprint(rw, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(rw http.ResponseWriter, ev Event) {
+	fmt.Fprintf(rw, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+}
+
+// Frames повертає Receiver, який публікує подію frame з номером кожного кадру та передає кадр у next, якщо він
+// заданий.
+func (e *Events) Frames(next painter.Receiver) painter.Receiver {
+	return &frameEvents{events: e, next: next}
+}
+
+type frameEvents struct {
+	events *Events
+	next   painter.Receiver
+	frame  uint64 // Кадри передаються лише з горутини циклу, тому лічильник не потребує синхронізації
+}
+
+func (f *frameEvents) Update(t screen.Texture) {
+	f.frame++
+	if f.next != nil {
+		f.next.Update(t)
+	}
+	f.events.Publish(EventFrame, map[string]any{"frame": f.frame})
+}
@@ -0,0 +1,164 @@
+package lang
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+)
+
+// readEvent зчитує наступну подію з потоку, пропускаючи коментарі
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	ev := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Read error: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" && len(ev) > 0 {
+			return ev
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok && name != "" {
+			ev[name] = value
+		}
+	}
+}
+
+func TestEvents_Resume(t *testing.T) {
+	var events Events
+	server := httptest.NewServer(&events)
+	defer server.Close()
+
+	events.Publish(EventError, map[string]string{"error": "bad"})
+	events.Publish(EventScene, map[string]int{"objects": 1})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	if ev := readEvent(t, r); ev["id"] != "2" || ev["event"] != EventScene || ev["data"] != `{"objects":1}` {
+		t.Errorf("Expected missed scene event, got %v", ev)
+	}
+
+	events.Publish(EventFrame, map[string]int{"frame": 1})
+	if ev := readEvent(t, r); ev["id"] != "3" || ev["event"] != EventFrame {
+		t.Errorf("Expected live frame event, got %v", ev)
+	}
+}
+
+func TestEvents_ServerTimeouts(t *testing.T) {
+	var events Events
+	s := NewServer("", &events)
+	s.ReadTimeout, s.WriteTimeout = 50*time.Millisecond, 50*time.Millisecond
+	addr := startServer(t, s)
+
+	resp, err := http.Get("http://" + addr)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Потік подій переживає обмеження часу сервера
+	time.Sleep(200 * time.Millisecond)
+	events.Publish(EventScene, map[string]int{"objects": 1})
+	if ev := readEvent(t, bufio.NewReader(resp.Body)); ev["event"] != EventScene {
+		t.Errorf("Expected scene event after the server timeouts, got %v", ev)
+	}
+}
+
+type nopReceiver struct{ updates int }
+
+func (r *nopReceiver) Update(screen.Texture) { r.updates++ }
+
+func TestEvents_FramesAndScripts(t *testing.T) {
+	var (
+		events Events
+		loop   painter.Loop
+		parser Parser
+		next   nopReceiver
+	)
+	ch, _ := events.subscribe(0)
+
+	frames := events.Frames(&next)
+	frames.Update(nil)
+	frames.Update(nil)
+	if next.updates != 2 {
+		t.Errorf("Expected frames to be passed on, got %d updates", next.updates)
+	}
+
+	h := &Handler{Loop: &loop, Parser: &parser, Events: &events}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("figure 0.5 0.5")))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("bogus")))
+
+	want := []struct{ typ, data string }{
+		{EventFrame, `{"frame":1}`},
+		{EventFrame, `{"frame":2}`},
+		{EventScene, `"objects":1`},
+		{EventError, `unknown command: bogus`},
+	}
+	for i, w := range want {
+		ev := <-ch
+		if ev.ID != uint64(i+1) || ev.Type != w.typ || !strings.Contains(ev.Data, w.data) {
+			t.Errorf("Event %d: expected %s %s, got %+v", i, w.typ, w.data, ev)
+		}
+	}
+}
+
+func TestEvents_HistoryGap(t *testing.T) {
+	var events Events
+	events.Publish(EventScene, map[string]int{"objects": 1})
+	for i := 0; i < 2*eventHistory; i++ {
+		events.Publish(EventFrame, map[string]int{"frame": i + 1})
+	}
+
+	// Події frame не витісняють з історії подію scene
+	_, missed := events.subscribe(1 + eventHistory)
+	if len(missed) != 0 {
+		t.Errorf("Expected no missed events after a frame, got %+v", missed)
+	}
+	_, missed = events.subscribe(0)
+	if len(missed) != 1 || missed[0].Type != EventScene {
+		t.Fatalf("Expected the scene event to stay in history, got %+v", missed)
+	}
+
+	for i := 0; i < eventHistory; i++ {
+		events.Publish(EventError, map[string]string{"error": "bad"})
+	}
+	_, missed = events.subscribe(1)
+	if len(missed) != eventHistory || missed[0].Type != EventError {
+		t.Errorf("Expected %d error events, got %d", eventHistory, len(missed))
+	}
+	_, missed = events.subscribe(0)
+	if missed[0].Type != EventError {
+		t.Errorf("Expected no reset for a new client, got %+v", missed[0])
+	}
+
+	// Перша подія error вже видалена з історії, тому клієнт, який бачив лише подію scene, отримує reset
+	events.Publish(EventError, map[string]string{"error": "bad"})
+	_, missed = events.subscribe(1)
+	if len(missed) != eventHistory+1 || missed[0].Type != EventReset || missed[0].ID != events.lastID {
+		t.Errorf("Expected reset event before the history, got %+v", missed[0])
+	}
+
+	// Після перезапуску сервера номери подій починаються заново
+	var restarted Events
+	restarted.Publish(EventScene, map[string]int{"objects": 1})
+	if _, missed = restarted.subscribe(5); len(missed) != 1 || missed[0].Type != EventReset {
+		t.Errorf("Expected reset event after restart, got %+v", missed)
+	}
+}
@@ -0,0 +1,283 @@
+package lang
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// functions функції, доступні у виразах; аргументи тригонометричних функцій задаються у градусах
+var functions = map[string]func(args []float64) (float64, error){
+	"abs":   unaryFunc(math.Abs),
+	"floor": unaryFunc(math.Floor),
+	"sqrt":  unaryFunc(math.Sqrt),
+	"sin":   unaryFunc(func(x float64) float64 { return math.Sin(x * math.Pi / 180) }),
+	"cos":   unaryFunc(func(x float64) float64 { return math.Cos(x * math.Pi / 180) }),
+	"min":   binaryFunc(math.Min),
+	"max":   binaryFunc(math.Max),
+}
+
+func unaryFunc(f func(float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		return f(args[0]), nil
+	}
+}
+
+func binaryFunc(f func(a, b float64) float64) func([]float64) (float64, error) {
+	return func(args []float64) (float64, error) {
+		if len(args) != 2 {
+			return 0, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		return f(args[0], args[1]), nil
+	}
+}
+
+// evaluate обчислює арифметичний вираз. Підтримуються числа, змінні $name, дужки, операції + - * / %,
+// порівняння < <= > >= == !=, логічні && || ! та функції з таблиці functions. Істина подається як 1, хиба - як 0.
+func evaluate(expr string, vars map[string]float64) (float64, error) {
+	e := &exprParser{src: expr, vars: vars}
+	v, err := e.or()
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression %s: %v", expr, err)
+	}
+	if e.skipSpaces(); e.pos < len(e.src) {
+		return 0, fmt.Errorf("invalid expression %s: unexpected %q", expr, e.src[e.pos:])
+	}
+	// Наприклад, sqrt(-1) або 1/0, які інакше потрапили б у координати як NaN або Inf
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid expression %s: result is %w", expr, errNotFinite)
+	}
+	return v, nil
+}
+
+// exprParser розбирає вираз методом рекурсивного спуску, одразу обчислюючи значення
+type exprParser struct {
+	src  string
+	pos  int
+	vars map[string]float64
+}
+
+func (e *exprParser) skipSpaces() {
+	for e.pos < len(e.src) && e.src[e.pos] == ' ' {
+		e.pos++
+	}
+}
+
+// accept пропускає оператор op, якщо він стоїть на поточній позиції
+func (e *exprParser) accept(op string) bool {
+	e.skipSpaces()
+	if strings.HasPrefix(e.src[e.pos:], op) {
+		e.pos += len(op)
+		return true
+	}
+	return false
+}
+
+func (e *exprParser) or() (float64, error) {
+	v, err := e.and()
+	for err == nil && e.accept("||") {
+		var r float64
+		if r, err = e.and(); err == nil {
+			v = truth(v != 0 || r != 0)
+		}
+	}
+	return v, err
+}
+
+func (e *exprParser) and() (float64, error) {
+	v, err := e.comparison()
+	for err == nil && e.accept("&&") {
+		var r float64
+		if r, err = e.comparison(); err == nil {
+			v = truth(v != 0 && r != 0)
+		}
+	}
+	return v, err
+}
+
+func (e *exprParser) comparison() (float64, error) {
+	v, err := e.sum()
+	if err != nil {
+		return 0, err
+	}
+	// Двосимвольні оператори перевіряються раніше за односимвольні
+	for _, op := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+		if !e.accept(op) {
+			continue
+		}
+		r, err := e.sum()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "<=":
+			return truth(v <= r), nil
+		case ">=":
+			return truth(v >= r), nil
+		case "==":
+			return truth(v == r), nil
+		case "!=":
+			return truth(v != r), nil
+		case "<":
+			return truth(v < r), nil
+		default:
+			return truth(v > r), nil
+		}
+	}
+	return v, nil
+}
+
+func (e *exprParser) sum() (float64, error) {
+	v, err := e.product()
+	for err == nil {
+		var r float64
+		switch {
+		case e.accept("+"):
+			r, err = e.product()
+			v += r
+		case e.accept("-"):
+			r, err = e.product()
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+func (e *exprParser) product() (float64, error) {
+	v, err := e.unaryFunc()
+	for err == nil {
+		var r float64
+		switch {
+		case e.accept("*"):
+			r, err = e.unaryFunc()
+			v *= r
+		case e.accept("/"), e.accept("%"):
+			op := e.src[e.pos-1]
+			if r, err = e.unaryFunc(); err != nil {
+				break
+			}
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == '/' {
+				v /= r
+			} else {
+				v = math.Mod(v, r)
+			}
+		default:
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+func (e *exprParser) unaryFunc() (float64, error) {
+	switch {
+	case e.accept("-"):
+		v, err := e.unaryFunc()
+		return -v, err
+	case e.accept("!"):
+		v, err := e.unaryFunc()
+		return truth(v == 0), err
+	}
+	return e.primary()
+}
+
+func (e *exprParser) primary() (float64, error) {
+	e.skipSpaces()
+	if e.accept("(") {
+		v, err := e.or()
+		if err != nil {
+			return 0, err
+		}
+		if !e.accept(")") {
+			return 0, fmt.Errorf("expected )")
+		}
+		return v, nil
+	}
+
+	if e.accept("$") {
+		name := e.identifier()
+		v, ok := e.vars[name]
+		if !ok {
+			return 0, fmt.Errorf("undefined variable $%s", name)
+		}
+		return v, nil
+	}
+
+	if name := e.identifier(); name != "" {
+		f, ok := functions[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown function %s", name)
+		}
+		if !e.accept("(") {
+			return 0, fmt.Errorf("expected ( after %s", name)
+		}
+		var args []float64
+		for !e.accept(")") {
+			if len(args) > 0 && !e.accept(",") {
+				return 0, fmt.Errorf("expected , or ) in %s arguments", name)
+			}
+			arg, err := e.or()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, arg)
+		}
+		v, err := f(args)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", name, err)
+		}
+		return v, nil
+	}
+
+	start := e.pos
+	for e.pos < len(e.src) && (e.src[e.pos] == '.' || unicode.IsDigit(rune(e.src[e.pos]))) {
+		e.pos++
+	}
+	if start == e.pos {
+		if e.pos == len(e.src) {
+			return 0, fmt.Errorf("unexpected end")
+		}
+		return 0, fmt.Errorf("unexpected %q", e.src[e.pos:])
+	}
+	return strconv.ParseFloat(e.src[start:e.pos], 64)
+}
+
+// identifier зчитує ім'я змінної або функції
+func (e *exprParser) identifier() string {
+	start := e.pos
+	for e.pos < len(e.src) && isIdentRune(rune(e.src[e.pos]), e.pos > start) {
+		e.pos++
+	}
+	return e.src[start:e.pos]
+}
+
+// isIdentRune перевіряє, чи може символ бути частиною імені; цифри допускаються лише не на початку
+func isIdentRune(r rune, inside bool) bool {
+	return r == '_' || (r < unicode.MaxASCII && unicode.IsLetter(r)) || (inside && unicode.IsDigit(r))
+}
+
+// isIdentifier перевіряє, чи рядок є коректним ім'ям змінної
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !isIdentRune(r, i > 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// defaultFigureName ім'я стандартної T-подібної фігури
+const defaultFigureName = "t"
+
+// parseFigure обробляє команду створення фігури:
+//
+//	figure [name] <x> <y> [scale=<k>] [rot=<deg>] [style]
+//
+// Ім'я посилається на фігуру, визначену командою define; без імені малюється стандартна T-подібна фігура.
+// Масштаб задається множником відносно початкового розміру, кут повороту - у градусах за годинниковою стрілкою.
+// Параметри стилю описані у parseStyle.
+func (p *Parser) parseFigure(fields []string) (*painter.FigureOperation, error) {
+	var shape painter.FigureShape
+	if len(fields) > 1 && isFigureName(fields[1]) {
+		if fields[1] != defaultFigureName {
+			var ok bool
+			if shape, ok = p.shapes[fields[1]]; !ok {
+				return nil, fmt.Errorf("unknown figure: %s", fields[1])
+			}
+		}
+		fields = append(fields[:1:1], fields[2:]...)
+	}
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid figure command format")
+	}
+
+	X, err := parseCoordinates(fields[1], "X")
+	if err != nil {
+		return nil, err
+	}
+	Y, err := parseCoordinates(fields[2], "Y")
+	if err != nil {
+		return nil, err
+	}
+	fig := &painter.FigureOperation{X: p.scale(X), Y: p.scale(Y), Shape: shape}
+
+	var st shapeStyle
+	for _, option := range fields[3:] {
+		switch {
+		case strings.HasPrefix(option, "scale="):
+			if fig.Scale, err = parseScale(strings.TrimPrefix(option, "scale=")); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(option, "rot="):
+			if fig.Rotation, err = parseAngle(strings.TrimPrefix(option, "rot=")); err != nil {
+				return nil, err
+			}
+		default:
+			ok, err := st.parseOption(option)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("invalid figure command format")
+			}
+		}
+	}
+	fig.Color, fig.Style = st.color, st.Style
+	return fig, nil
+}
+
+// parseTransform обробляє команди трансформації фігур:
+//
+//	rotate <target> <deg>
+//	scale <target> <k>
+//
+// Ціль задається так само, як у команді animate. Команди встановлюють абсолютні значення кута та масштабу.
+func (p *Parser) parseTransform(fields []string) (painter.Operation, error) {
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid %s command format", fields[0])
+	}
+
+	figures, err := p.targetFigures(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[0] == "rotate" {
+		deg, err := parseAngle(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		p.updateKeys(fields[1], func(key *figureKey) { key.angle = deg })
+		return painter.RotateFiguresOperation{Degrees: deg, Figures: figures}, nil
+	}
+
+	k, err := parseScale(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	p.updateKeys(fields[1], func(key *figureKey) { key.scale = k })
+	return painter.ScaleFiguresOperation{Factor: k, Figures: figures}, nil
+}
+
+// parseAngle отримує кут у градусах
+func parseAngle(raw string) (float64, error) {
+	deg, err := parseFloat(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid angle value: %v", err)
+	}
+	return deg, nil
+}
+
+// parseScale отримує масштаб, який має бути додатним
+func parseScale(raw string) (float64, error) {
+	k, err := parseFloat(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scale value: %v", err)
+	}
+	if k <= 0 {
+		return 0, fmt.Errorf("scale value %.2f must be positive", k)
+	}
+	return k, nil
+}
+
+// definition фігура, яка описується блоком define
+type definition struct {
+	name  string
+	shape painter.FigureShape
+}
+
+// startDefinition починає блок визначення фігури:
+//
+//	define <name> {
+//	  rect <x1> <y1> <x2> <y2> [#color]
+//	  circle <x> <y> <r> [#color]
+//	  ellipse <x> <y> <rx> <ry> [#color]
+//	  polygon <x1> <y1> <x2> <y2> <x3> <y3> ... [#color]
+//	}
+//
+// Координати частин задаються у нормалізованих одиницях відносно центру фігури і можуть бути від'ємними.
+// Частини без кольору малюються кольором фігури. Повторне визначення замінює попереднє.
+func (p *Parser) startDefinition(fields []string) error {
+	if len(fields) != 2 {
+		return fmt.Errorf("invalid define command format: expected define <name> {")
+	}
+	name := fields[1]
+	if !isFigureName(name) {
+		return fmt.Errorf("invalid figure name %s: must start with a letter", name)
+	}
+	if name == defaultFigureName {
+		return fmt.Errorf("figure %s is built in and cannot be redefined", name)
+	}
+	p.defining = &definition{name: name}
+	return nil
+}
+
+// parseDefinition обробляє рядок усередині блоку define
+func (p *Parser) parseDefinition(fields []string) error {
+	part, err := p.parsePart(fields)
+	if err != nil {
+		return fmt.Errorf("define %s: %v", p.defining.name, err)
+	}
+	p.defining.shape = append(p.defining.shape, part)
+	return nil
+}
+
+// endDefinition завершує блок define та зберігає визначену фігуру
+func (p *Parser) endDefinition() error {
+	def := p.defining
+	p.defining = nil
+	if len(def.shape) == 0 {
+		return fmt.Errorf("figure %s has no parts", def.name)
+	}
+	if p.shapes == nil {
+		p.shapes = make(map[string]painter.FigureShape)
+	}
+	p.shapes[def.name] = def.shape
+	return nil
+}
+
+// parsePart розбирає одну частину складеної фігури
+func (p *Parser) parsePart(fields []string) (painter.FigurePart, error) {
+	command := fields[0]
+	fields, st, err := parseStyle(fields)
+	if err != nil {
+		return painter.FigurePart{}, err
+	}
+	if st.Style != (painter.Style{}) {
+		return painter.FigurePart{}, fmt.Errorf("only color can be set for figure part")
+	}
+	values, err := p.parseOffsets(fields[1:])
+	if err != nil {
+		return painter.FigurePart{}, err
+	}
+
+	switch command {
+	case "rect":
+		if len(values) != 4 {
+			return painter.FigurePart{}, fmt.Errorf("invalid rect part format")
+		}
+		return painter.RectPart(values[0], values[1], values[2], values[3], st.color), nil
+	case "circle", "ellipse":
+		if command == "circle" && len(values) == 3 {
+			values = append(values, values[2]) // Коло - еліпс з однаковими радіусами
+		} else if command == "circle" || len(values) != 4 {
+			return painter.FigurePart{}, fmt.Errorf("invalid %s part format", command)
+		}
+		if values[2] <= 0 || values[3] <= 0 {
+			return painter.FigurePart{}, fmt.Errorf("%s radius must be positive", command)
+		}
+		return painter.EllipsePart(values[0], values[1], values[2], values[3], st.color), nil
+	case "polygon":
+		if len(values) < 6 || len(values)%2 != 0 {
+			return painter.FigurePart{}, fmt.Errorf("invalid polygon part format: expected at least 3 points")
+		}
+		pts := make([]painter.Point, len(values)/2)
+		for i := range pts {
+			pts[i] = painter.Point{X: values[2*i], Y: values[2*i+1]}
+		}
+		return painter.FigurePart{Points: pts, Color: st.color}, nil
+	}
+	return painter.FigurePart{}, fmt.Errorf("unknown figure part: %s", command)
+}
+
+// parseOffsets перевіряє та перетворює зміщення відносно центру фігури: від -1 до 1 у нормалізованих одиницях
+func (p *Parser) parseOffsets(raw []string) ([]float64, error) {
+	values := make([]float64, len(raw))
+	for i, r := range raw {
+		v, err := parseFloat(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d value: %v", i+1, err)
+		}
+		if v < -1 || v > 1 {
+			return nil, fmt.Errorf("argument %d value %.2f out of range [-1.0 - 1.0]", i+1, v)
+		}
+		values[i] = p.scale(v)
+	}
+	return values, nil
+}
+
+// isFigureName перевіряє, чи поле є ім'ям фігури, а не координатою
+func isFigureName(field string) bool {
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsLetter(r)
+}
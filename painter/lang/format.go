@@ -0,0 +1,101 @@
+package lang
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// formatPrecision кількість знаків після коми, до якої округлюються числа під час форматування
+const formatPrecision = 4
+
+// Format повертає скрипт у канонічному вигляді: кожна команда на окремому рядку, поля розділені одним пробілом,
+// вкладені блоки мають відступ у два пробіли, а числа записані без зайвих нулів з точністю formatPrecision знаків.
+// Коментарі та одиночні порожні рядки між командами зберігаються. Скрипт з синтаксичними помилками не форматується.
+func Format(in io.Reader) (string, error) {
+	block, err := readScript(in)
+	if err != nil {
+		return "", err
+	}
+	f := &formatter{}
+	f.block(block, 0)
+	if len(f.lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(f.lines, "\n") + "\n", nil
+}
+
+// formatter збирає рядки відформатованого скрипта
+type formatter struct {
+	lines []string
+}
+
+func (f *formatter) block(block []*statement, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for i, st := range block {
+		if st.trailing && len(f.lines) > 0 {
+			f.lines[len(f.lines)-1] += " " + st.comment
+			continue
+		}
+		if i > 0 && st.line > block[i-1].end+1 {
+			f.lines = append(f.lines, "")
+		}
+		if st.comment != "" {
+			f.lines = append(f.lines, indent+st.comment)
+			continue
+		}
+
+		line := indent + formatFields(st.fields)
+		if !st.block {
+			f.lines = append(f.lines, line)
+			continue
+		}
+		f.lines = append(f.lines, line+" {")
+		f.block(st.body, depth+1)
+		if st.hasElse {
+			f.lines = append(f.lines, indent+"} else {")
+			f.block(st.elseBody, depth+1)
+		}
+		f.lines = append(f.lines, indent+"}")
+	}
+}
+
+// formatFields записує поля команди через пробіл
+func formatFields(fields []field) string {
+	res := make([]string, len(fields))
+	for i, fl := range fields {
+		res[i] = formatField(fl)
+	}
+	return strings.Join(res, " ")
+}
+
+// formatField повертає канонічний запис поля: текст у лапках екранується, а числа та числові значення
+// параметрів на зразок x=0.50 скорочуються
+func formatField(fl field) string {
+	if fl.quoted {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(fl.text) + `"`
+	}
+
+	prefix, value := "", fl.text
+	if i := strings.IndexByte(value, '='); i > 0 && isIdentifier(value[:i]) {
+		prefix, value = value[:i+1], value[i+1:]
+	}
+	if isNumber(value) {
+		v, _ := strconv.ParseFloat(value, 64)
+		k := math.Pow(10, formatPrecision)
+		if v = math.Round(v*k) / k; v == 0 {
+			v = 0 // Без знака мінус у -0
+		}
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return prefix + value
+}
+
+// isNumber перевіряє, чи поле є десятковим числом
+func isNumber(s string) bool {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return false
+	}
+	return strings.Trim(s, "0123456789.-+eE") == "" && strings.ContainsAny(s, "0123456789")
+}
@@ -0,0 +1,54 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	input := `# Сітка
+let   n = 3
+repeat $n i { figure   0.50000 0.1 # центр
+if $i > 1 { update } else {
+move 0.2 -0.0 }
+
+}
+
+
+text 0.1 0.2 0.05 "a \"b\"" left
+circle x=0.123456 y=0.5 r=.1;update
+`
+	want := `# Сітка
+let n = 3
+repeat $n i {
+  figure 0.5 0.1 # центр
+  if $i > 1 {
+    update
+  } else {
+    move 0.2 0
+  }
+}
+
+text 0.1 0.2 0.05 "a \"b\"" left
+circle x=0.1235 y=0.5 r=0.1
+update
+`
+	got, err := Format(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Unexpected result:\n%s\nexpected:\n%s", got, want)
+	}
+
+	again, err := Format(strings.NewReader(got))
+	if err != nil || again != got {
+		t.Errorf("Formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	if _, err := Format(strings.NewReader("repeat 2 {\n  white\n")); err == nil {
+		t.Error("Expected error for unclosed block")
+	}
+}
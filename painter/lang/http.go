@@ -1,31 +1,125 @@
 package lang
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
 )
 
+// Handler обробляє HTTP запити з командами: дані з запиту віддає у Parser, а потім відправляє отриманий список
+// операцій у painter.Loop.
+type Handler struct {
+	Loop    *painter.Loop
+	Parser  *Parser
+	Journal *Journal // Якщо заданий, кожен прийнятий скрипт записується у журнал
+	Events  *Events  // Якщо задані, прийняті та відхилені скрипти публікуються як події scene та error
+
+	MaxBodySize int64 // Максимальний розмір тіла запиту в байтах; 0 означає DefaultMaxBodySize
+}
+
+// DefaultMaxBodySize розмір тіла запиту зі скриптом, якщо Handler.MaxBodySize не задано.
+const DefaultMaxBodySize = 1 << 20
+
 // HttpHandler конструює обробник HTTP запитів, який дані з запиту віддає у Parser, а потім відправляє отриманий список
 // операцій у painter.Loop.
 func HttpHandler(loop *painter.Loop, p *Parser) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		var in io.Reader = r.Body
-		if r.Method == http.MethodGet {
-			in = strings.NewReader(r.URL.Query().Get("cmd"))
-		}
+	return &Handler{Loop: loop, Parser: p}
+}
 
-		cmds, err := p.Parse(in)
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var script string
+	if r.Method == http.MethodGet {
+		script = r.URL.Query().Get("cmd")
+	} else {
+		limit := h.maxBodySize()
+		body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, limit))
 		if err != nil {
-			log.Printf("Bad script: %s", err)
+			log.Printf("Failed to read script: %s", err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(rw, fmt.Sprintf("script is larger than %d bytes", limit), http.StatusRequestEntityTooLarge)
+				return
+			}
 			rw.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		script = string(body)
+	}
+
+	warnings, err := h.execute(r.RemoteAddr, script, false)
+	if err != nil {
+		// У відповіді перелічуються всі помилки скрипта з номерами рядків
+		log.Printf("Bad script: %s", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrTooManyLines) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(rw, err.Error(), status)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+
+	// Попередження не заважають виконанню скрипта, тому вони повертаються у тілі успішної відповіді
+	for _, w := range warnings {
+		fmt.Fprintln(rw, w)
+	}
+}
 
-		loop.Post(painter.OperationList(cmds))
-		rw.WriteHeader(http.StatusOK)
+func (h *Handler) maxBodySize() int64 {
+	if h.MaxBodySize == 0 {
+		return DefaultMaxBodySize
+	}
+	return h.MaxBodySize
+}
+
+// execute виконує скрипт, отриманий від клієнта remote: записує його у журнал та відправляє операції у цикл.
+// line означає рядок потоку команд, для якого не перевіряється наявність update (див. Parser.parseScript).
+func (h *Handler) execute(remote, script string, line bool) ([]*ScriptError, error) {
+	cmds, warnings, err := h.Parser.parseScript(strings.NewReader(script), line, func(cmds []painter.Operation) {
+		// Одночасні запити записуються в журнал у тому порядку, в якому парсер їх виконав, щоб відтворення
+		// журналу давало ту саму сцену
+		if h.Journal != nil {
+			if err := h.Journal.Record(remote, script); err != nil {
+				log.Printf("Failed to record script: %s", err)
+			}
+		}
+		h.Loop.Post(painter.OperationList(cmds))
+	})
+	if err != nil {
+		if h.Events != nil {
+			h.Events.Publish(EventError, map[string]any{"remote": remote, "error": err.Error()})
+		}
+		return nil, err
+	}
+
+	if h.Events != nil && len(cmds) > 0 {
+		h.Events.Publish(EventScene, map[string]any{"remote": remote, "objects": h.Parser.objects()})
+	}
+	return warnings, nil
+}
+
+// SVGHandler конструює обробник HTTP запитів, який повертає поточну сцену у форматі SVG. Знімок сцени робиться
+// всередині painter.Loop, тому він враховує всі операції, надіслані раніше.
+func SVGHandler(loop *painter.Loop, p *Parser) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		scene := make(chan painter.Scene, 1)
+		loop.Post(painter.OperationFunc(func(screen.Texture) {
+			scene <- p.Scene()
+		}))
+
+		select {
+		case s := <-scene:
+			rw.Header().Set("Content-Type", "image/svg+xml")
+			if err := s.WriteSVG(rw); err != nil {
+				log.Printf("Failed to write SVG: %s", err)
+			}
+		case <-r.Context().Done():
+		}
 	})
 }
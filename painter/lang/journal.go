@@ -0,0 +1,135 @@
+package lang
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// JournalEntry описує один прийнятий скрипт у журналі команд.
+type JournalEntry struct {
+	Time   time.Time `json:"time"`   // Час отримання скрипта
+	Remote string    `json:"remote"` // Адреса клієнта, який надіслав скрипт
+	Script string    `json:"script"` // Текст скрипта
+}
+
+// Journal записує прийняті скрипти у журнал, лише доповнюючи його. Кожен запис зберігається окремим рядком JSON.
+type Journal struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewJournal створює журнал, який записує скрипти у w.
+func NewJournal(w io.Writer) *Journal {
+	return &Journal{w: w, now: time.Now}
+}
+
+// OpenJournal відкриває файл журналу для доповнення, створюючи його за потреби.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJournal(f), nil
+}
+
+// Close закриває файл журналу, якщо журнал був відкритий через OpenJournal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if c, ok := j.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Record додає скрипт, отриманий від клієнта remote, у журнал.
+func (j *Journal) Record(remote, script string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := json.Marshal(JournalEntry{Time: j.now(), Remote: remote, Script: script})
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadJournal зчитує всі записи журналу. Довжина рядка не обмежується, бо Record записує скрипти будь-якого
+// розміру, а екранування в JSON робить запис довшим за сам скрипт.
+func ReadJournal(in io.Reader) ([]JournalEntry, error) {
+	var res []JournalEntry
+
+	r := bufio.NewReader(in)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			var e JournalEntry
+			if err := json.Unmarshal(data, &e); err != nil {
+				return nil, fmt.Errorf("invalid journal entry on line %d: %v", line, err)
+			}
+			res = append(res, e)
+		}
+		if err != nil {
+			return res, nil
+		}
+	}
+}
+
+// Replayer повторно відправляє записи журналу через Parser у painter.Loop.
+type Replayer struct {
+	Loop   *painter.Loop
+	Parser *Parser
+
+	// Speed задає коефіцієнт прискорення відносно початкових інтервалів між записами: 1 — оригінальна швидкість,
+	// 2 — удвічі швидше. Нуль означає відтворення без затримок.
+	Speed float64
+
+	// Step, якщо заданий, викликається перед кожним записом замість очікування (покроковий режим).
+	// Помилка, повернута з Step, зупиняє відтворення.
+	Step func(e JournalEntry) error
+
+	sleep func(d time.Duration)
+}
+
+// Replay відтворює записи у порядку їх отримання.
+func (r *Replayer) Replay(entries []JournalEntry) error {
+	sleep := r.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	for i, e := range entries {
+		if r.Step != nil {
+			if err := r.Step(e); err != nil {
+				return err
+			}
+		} else if i > 0 && r.Speed > 0 {
+			if d := e.Time.Sub(entries[i-1].Time); d > 0 {
+				sleep(time.Duration(float64(d) / r.Speed))
+			}
+		}
+
+		cmds, err := r.Parser.Parse(strings.NewReader(e.Script))
+		if err != nil {
+			return fmt.Errorf("entry %d from %s: %v", i+1, e.Remote, err)
+		}
+		r.Loop.Post(painter.OperationList(cmds))
+	}
+	return nil
+}
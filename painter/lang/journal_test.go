@@ -0,0 +1,148 @@
+package lang
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+func TestJournal_RecordAndRead(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	j := NewJournal(&buf)
+	j.now = func() time.Time { return start }
+	if err := j.Record("127.0.0.1:1000", "white\nupdate"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	j.now = func() time.Time { return start.Add(time.Second) }
+	if err := j.Record("127.0.0.1:1001", "figure 0.5 0.5"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	entries, err := ReadJournal(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := []JournalEntry{
+		{Time: start, Remote: "127.0.0.1:1000", Script: "white\nupdate"},
+		{Time: start.Add(time.Second), Remote: "127.0.0.1:1001", Script: "figure 0.5 0.5"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Unexpected entries: got %+v, want %+v", entries, expected)
+	}
+}
+
+func TestHandler_JournalOrder(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+		buf    bytes.Buffer
+	)
+	h := &Handler{Loop: &loop, Parser: &parser, Journal: NewJournal(&buf)}
+
+	// Останній записаний скрипт має бути тим, який парсер виконав останнім
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.execute("client", fmt.Sprintf("bgrect 0 0 0.%02d 0.5\nupdate", i+1), false)
+		}()
+	}
+	wg.Wait()
+
+	entries, err := ReadJournal(&buf)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	replayed := &Parser{}
+	if err := (&Replayer{Loop: &loop, Parser: replayed}).Replay(entries); err != nil {
+		t.Fatalf("Replay error: %v", err)
+	}
+	if *replayed.currentRect != *parser.currentRect {
+		t.Errorf("Replayed scene differs: got %+v, want %+v", *replayed.currentRect, *parser.currentRect)
+	}
+}
+
+func TestJournal_ReadLongEntry(t *testing.T) {
+	var buf bytes.Buffer
+	script := strings.Repeat("text 0 0 0.1 \"\\\"\"\n", DefaultMaxBodySize/16)
+	if err := NewJournal(&buf).Record("client", script); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if buf.Len() <= DefaultMaxBodySize {
+		t.Fatalf("Expected the entry to be longer than the body limit, got %d bytes", buf.Len())
+	}
+
+	entries, err := ReadJournal(&buf)
+	if err != nil || len(entries) != 1 || entries[0].Script != script {
+		t.Errorf("Expected the long entry to be read back, got %d entries (%v)", len(entries), err)
+	}
+}
+
+func TestJournal_ReadInvalidEntry(t *testing.T) {
+	_, err := ReadJournal(strings.NewReader("{}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected invalid entry error on line 2, got %v", err)
+	}
+}
+
+func TestReplayer_Speed(t *testing.T) {
+	start := time.Now()
+	entries := []JournalEntry{
+		{Time: start, Script: "white"},
+		{Time: start.Add(2 * time.Second), Script: "figure 0.5 0.5"},
+		{Time: start.Add(3 * time.Second), Script: "figure 0.2 0.2"},
+	}
+
+	var (
+		loop   painter.Loop
+		parser Parser
+		sleeps []time.Duration
+	)
+	r := Replayer{Loop: &loop, Parser: &parser, Speed: 2}
+	r.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	if err := r.Replay(entries); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !reflect.DeepEqual(sleeps, []time.Duration{time.Second, 500 * time.Millisecond}) {
+		t.Errorf("Unexpected delays: %v", sleeps)
+	}
+	if n := len(parser.Scene().Operations); n != 2 {
+		t.Errorf("Expected 2 figures after replay, got %d", n)
+	}
+}
+
+func TestReplayer_StepStops(t *testing.T) {
+	entries := []JournalEntry{{Script: "white"}, {Script: "green"}}
+	stop := errors.New("stop")
+
+	var (
+		loop   painter.Loop
+		parser Parser
+		steps  int
+	)
+	r := Replayer{Loop: &loop, Parser: &parser, Step: func(JournalEntry) error {
+		steps++
+		if steps == 2 {
+			return stop
+		}
+		return nil
+	}}
+
+	if err := r.Replay(entries); !errors.Is(err, stop) {
+		t.Errorf("Expected replay to stop with step error, got %v", err)
+	}
+	if parser.Scene().Background == nil {
+		t.Error("Expected first entry to be replayed")
+	}
+}
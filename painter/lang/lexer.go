@@ -0,0 +1,198 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Граматика мови команд:
+//
+//	script    = { statement } .
+//	statement = fields [ block [ "else" block ] ] end .
+//	block     = "{" { statement } "}" .
+//	fields    = field { field } .
+//	end       = новий рядок | ";" | "}" .
+//
+// Поле - це послідовність символів без пробілів, текст у подвійних лапках або вираз у дужках, який може містити
+// пробіли. Коментар починається з // або з # після якого йде пробіл і триває до кінця рядка; # одразу перед
+// символами, як у #f00, позначає колір.
+
+// field поле команди
+type field struct {
+	text   string
+	quoted bool // Поле містило текст у лапках, тому не обчислюється як вираз
+	col    int  // Зміщення початку поля у рядку в байтах
+}
+
+// tokenKind тип лексеми
+type tokenKind int
+
+const (
+	tokenField   tokenKind = iota // Поле команди
+	tokenLBrace                   // Початок блоку {
+	tokenRBrace                   // Кінець блоку }
+	tokenEnd                      // Кінець команди: новий рядок або ;
+	tokenComment                  // Коментар до кінця рядка
+)
+
+// token лексема скрипта
+type token struct {
+	kind tokenKind
+	field
+	line     int  // Номер рядка, починаючи з 1
+	trailing bool // Коментар стоїть у рядку після команди
+}
+
+// lexer розбиває текст скрипта на лексеми. Помилки не зупиняють розбір: рядок з помилкою пропускається, а
+// помилка додається до errs.
+type lexer struct {
+	tokens []token
+	errs   []error
+	line   int
+
+	current strings.Builder
+	start   int // Зміщення початку поточного поля
+	inField bool
+	quoted  bool
+	depth   int
+	hasText bool
+}
+
+// lex розбиває скрипт на лексеми
+func lex(src string) ([]token, []error) {
+	l := &lexer{line: 1}
+	for l.line = 1; src != ""; l.line++ {
+		line, rest, _ := strings.Cut(src, "\n")
+		l.lexLine(line)
+		src = rest
+	}
+	return l.tokens, l.errs
+}
+
+// lexLine розбиває один рядок скрипта
+func (l *lexer) lexLine(line string) {
+	start := len(l.tokens)
+	l.current.Reset()
+	l.inField, l.quoted, l.depth, l.hasText = false, false, 0, false
+
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			l.current.WriteRune(r)
+			escaped = false
+		case l.quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			l.begin(i)
+			l.quoted = !l.quoted
+			l.hasText = true
+		case l.quoted:
+			l.current.WriteRune(r)
+		case r == '(':
+			l.begin(i)
+			l.depth++
+			l.current.WriteRune(r)
+		case r == ')':
+			if l.depth == 0 {
+				l.fail(start, "unbalanced parentheses")
+				return
+			}
+			l.depth--
+			l.current.WriteRune(r)
+		case l.depth > 0:
+			l.current.WriteRune(r)
+		case !l.inField && isComment(line[i:]):
+			l.tokens = append(l.tokens, token{
+				kind: tokenComment, field: field{text: strings.TrimRight(line[i:], " \t\r"), col: i},
+				line: l.line, trailing: len(l.tokens) > start,
+			})
+			l.emit(tokenEnd, "")
+			return
+		case r == ' ' || r == '\t' || r == '\r':
+			l.flush()
+		case r == ';':
+			l.flush()
+			l.emit(tokenEnd, ";")
+		case r == '{':
+			l.flush()
+			l.emit(tokenLBrace, "{")
+		case r == '}':
+			l.flush()
+			l.emit(tokenRBrace, "}")
+		default:
+			l.begin(i)
+			l.current.WriteRune(r)
+		}
+	}
+
+	switch {
+	case l.quoted:
+		l.fail(start, "unterminated quoted string")
+	case l.depth != 0:
+		l.fail(start, "unbalanced parentheses")
+	default:
+		l.flush()
+		l.emit(tokenEnd, "")
+	}
+}
+
+// isComment перевіряє, чи з поточної позиції починається коментар
+func isComment(rest string) bool {
+	return strings.HasPrefix(rest, "//") || rest == "#" ||
+		strings.HasPrefix(rest, "# ") || strings.HasPrefix(rest, "#\t")
+}
+
+// begin починає нове поле з позиції i, якщо поле ще не почалося
+func (l *lexer) begin(i int) {
+	if !l.inField {
+		l.inField, l.start = true, i
+	}
+}
+
+// flush завершує поточне поле
+func (l *lexer) flush() {
+	if l.inField {
+		l.tokens = append(l.tokens, token{kind: tokenField, field: field{l.current.String(), l.hasText, l.start}, line: l.line})
+	}
+	l.current.Reset()
+	l.inField, l.hasText = false, false
+}
+
+func (l *lexer) emit(kind tokenKind, text string) {
+	l.tokens = append(l.tokens, token{kind: kind, field: field{text: text}, line: l.line})
+}
+
+// fail відкидає лексеми рядка з помилкою, залишаючи лише кінець команди
+func (l *lexer) fail(start int, msg string) {
+	l.errs = append(l.errs, &ScriptError{Line: l.line, Err: fmt.Errorf("%s", msg)})
+	l.tokens = l.tokens[:start]
+	l.emit(tokenEnd, "")
+}
+
+// ScriptError помилка у рядку скрипта.
+type ScriptError struct {
+	Line    int  // Номер рядка, починаючи з 1; 0 якщо помилка стосується всього скрипта
+	Column  int  // Номер байта початку поля з помилкою, починаючи з 1; 0 якщо помилка стосується всього рядка
+	Length  int  // Довжина поля з помилкою в байтах
+	Warning bool // Попередження про підозрілу конструкцію, яке не заважає виконанню скрипта
+	Err     error
+}
+
+func (e *ScriptError) Error() string {
+	var prefix string
+	switch {
+	case e.Column > 0:
+		prefix = fmt.Sprintf("line %d:%d: ", e.Line, e.Column)
+	case e.Line > 0:
+		prefix = fmt.Sprintf("line %d: ", e.Line)
+	}
+	if e.Warning {
+		prefix += "warning: "
+	}
+	return fmt.Sprintf("%s%v", prefix, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,80 @@
+package lang
+
+import (
+	"fmt"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// lint перевіряє щойно виконану команду на конструкції, які не є помилками, але найімовірніше дають не той
+// результат, на який розраховував автор скрипта
+func (p *Parser) lint(s *script, line int, fields []string) {
+	s.commands++
+	warn := func(format string, args ...any) {
+		s.warnings = append(s.warnings, &ScriptError{Line: line, Warning: true, Err: fmt.Errorf(format, args...)})
+	}
+
+	switch fields[0] {
+	case "move":
+		if len(p.figureOperations) == 0 {
+			warn("move has no figures to move")
+		}
+		s.figures = nil // Положення фігур змінилися, індекс буде побудовано заново
+	case "animate":
+		if fields[1] == "all" && len(p.figureOperations) == 0 {
+			warn("animate has no figures to move")
+		}
+		s.figures = nil
+	case "rotate", "scale":
+		s.figures = nil
+	case "bgrect":
+		if r := p.currentRect; r.X1 > r.X2 || r.Y1 > r.Y2 {
+			warn("bgrect corners are inverted: expected top left corner first")
+		}
+	case "reset":
+		s.figures = nil // Фігури до reset більше не перекриваються з новими
+	case "figure":
+		// Положення беруться зі стану парсера, а не з фігур, які цикл подій змінює без блокування
+		last := len(p.figureKeys) - 1
+		if s.figures == nil {
+			// Фігури попередніх скриптів індексуються один раз на скрипт і після команд, що змінюють положення
+			s.figures = make(map[figureKey]int, len(p.figureKeys))
+			for i, key := range p.figureKeys[:last] {
+				if _, ok := s.figures[key]; !ok {
+					s.figures[key] = i
+				}
+			}
+		}
+		key := p.figureKeys[last]
+		if i, ok := s.figures[key]; ok {
+			warn("figure overlaps identical figure %d at the same position", i)
+		} else {
+			s.figures[key] = last
+		}
+	}
+}
+
+// lintScript перевіряє скрипт після виконання всіх команд
+func (p *Parser) lintScript(s *script) {
+	if s.commands > 0 && p.updateOperation == nil && len(p.animations) == 0 {
+		s.warnings = append(s.warnings, &ScriptError{
+			Warning: true, Err: fmt.Errorf("script has no update command: changes are not shown until the next update"),
+		})
+	}
+}
+
+// figureKey форма, положення та трансформація фігури; однакові ключі мають фігури, які повністю перекриваються
+type figureKey struct {
+	shape              *painter.FigurePart // Перша частина форми; форми, визначені командою define, не копіюються
+	parts              int
+	x, y, scale, angle float64
+}
+
+// keyOf повертає ключ щойно створеної фігури, яку ще не отримав цикл подій
+func keyOf(fig *painter.FigureOperation) figureKey {
+	key := figureKey{parts: len(fig.Shape), x: fig.X, y: fig.Y, scale: fig.Scale, angle: fig.Rotation}
+	if len(fig.Shape) > 0 {
+		key.shape = &fig.Shape[0]
+	}
+	return key
+}
@@ -0,0 +1,103 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_ParseWithWarnings(t *testing.T) {
+	input := `move 0.1 0.1
+figure 0.5 0.5
+figure 0.5 0.5 rot=45
+figure 0.5 0.5
+bgrect 0.9 0.9 0.1 0.1
+animate all 0.2 0.2 1s`
+
+	_, warnings, err := (&Parser{}).ParseWithWarnings(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []struct {
+		line    int
+		message string
+	}{
+		{1, "move has no figures"},
+		{4, "overlaps identical figure 0"},
+		{5, "bgrect corners are inverted"},
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Expected %d warnings, got %v", len(want), warnings)
+	}
+	for i, w := range want {
+		if e := warnings[i]; e.Line != w.line || !e.Warning || !strings.Contains(e.Error(), w.message) {
+			t.Errorf("Warning %d: expected line %d %q, got %v", i, w.line, w.message, e)
+		}
+	}
+}
+
+func TestParser_ParseWithWarnings_NoUpdate(t *testing.T) {
+	p := &Parser{}
+	_, warnings, err := p.ParseWithWarnings(strings.NewReader("white\nfigure 0.5 0.5"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 0 || !strings.Contains(warnings[0].Error(), "no update") {
+		t.Errorf("Expected missing update warning, got %v", warnings)
+	}
+
+	_, warnings, _ = p.ParseWithWarnings(strings.NewReader("reset\nfigure 0.5 0.5\nupdate"))
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestParser_ParseWithWarnings_ManyFigures(t *testing.T) {
+	p := &Parser{}
+	if _, err := p.Parse(strings.NewReader("figure 0.5 0.5\nupdate")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	_, warnings, err := p.ParseWithWarnings(strings.NewReader("repeat 50000 i {\nfigure ($i / 50000) 0.25\n}\nfigure 0.5 0.5\nupdate"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "overlaps identical figure 0") {
+		t.Errorf("Expected one overlap with the figure from the previous script, got %v", warnings)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Expected overlap check to be linear, took %v", d)
+	}
+
+	// Після reset попередні фігури не перекриваються з новими
+	_, warnings, _ = p.ParseWithWarnings(strings.NewReader("figure 0.5 0.5\nreset\nfigure 0.5 0.5\nupdate"))
+	if len(warnings) != 1 || warnings[0].Line != 1 {
+		t.Errorf("Expected only the overlap before reset, got %v", warnings)
+	}
+}
+
+func TestParser_ParseWithWarnings_MovedFigures(t *testing.T) {
+	p := &Parser{}
+	if _, err := p.Parse(strings.NewReader("figure 0.5 0.5\nupdate")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Перекриття перевіряється з положеннями після move та rotate, навіть якщо цикл їх ще не виконав
+	input := `move 0.2 0.2
+figure 0.5 0.5
+rotate 0 45
+figure 0.2 0.2
+figure 0.2 0.2 rot=45
+update`
+	_, warnings, err := p.ParseWithWarnings(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Line != 5 || !strings.Contains(warnings[0].Error(), "identical figure 0") {
+		t.Errorf("Expected overlap with the moved figure on line 5, got %v", warnings)
+	}
+	if p.figureOperations[0].X == p.scale(0.2) {
+		t.Error("Parser must not move figures itself")
+	}
+}
@@ -0,0 +1,338 @@
+// Package lsp реалізує сервер Language Server Protocol для мови команд painter: діагностику помилок,
+// доповнення назв команд та аргументів і підказки з описом команд.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"github.com/dk872/architecture-lab3/painter/lang"
+)
+
+// Server обслуговує одного клієнта LSP через потоки введення та виведення.
+type Server struct {
+	Scripts fs.FS // Каталог скриптів для команди include, як у сервера painter
+
+	out      io.Writer
+	docs     map[string]string // Тексти відкритих документів за URI
+	shutdown bool
+}
+
+// Serve обробляє повідомлення з in та записує відповіді в out до повідомлення exit або кінця потоку.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	s.docs = make(map[string]string)
+
+	r := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(r)
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			if err := s.reply(nil, nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			continue // Сповіщення не потребують відповіді
+		}
+		errors.As(err, &rpcErr)
+		if err != nil && rpcErr == nil {
+			rpcErr = &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if err := s.reply(msg.ID, result, rpcErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, rpcErr *responseError) error {
+	msg := map[string]any{"id": id}
+	if rpcErr != nil {
+		msg["error"] = rpcErr
+	} else {
+		msg["result"] = result
+	}
+	return writeMessage(s.out, msg)
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, map[string]any{"method": method, "params": params})
+}
+
+// handle обробляє запит або сповіщення та повертає результат
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // Клієнт надсилає повний текст документа
+				"completionProvider": map[string]any{"triggerCharacters": []string{" "}},
+				"hoverProvider":      true,
+			},
+			"serverInfo": map[string]any{"name": "painter-lsp"},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   textDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			return nil, s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publish(params.TextDocument.URI, nil)
+	case "textDocument/completion", "textDocument/hover":
+		var params struct {
+			TextDocument textDocument `json:"textDocument"`
+			Position     position     `json:"position"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		line, col := s.line(params.TextDocument.URI, params.Position)
+		if msg.Method == "textDocument/completion" {
+			return complete(line, col), nil
+		}
+		return hover(line, col), nil
+	}
+	if msg.ID == nil {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+type textDocument struct {
+	URI string `json:"uri"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+// Рівні важливості діагностики LSP
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+type diagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// update зберігає новий текст документа та надсилає його діагностику
+func (s *Server) update(uri, text string) error {
+	s.docs[uri] = text
+	return s.publish(uri, diagnose(text, s.Scripts))
+}
+
+func (s *Server) publish(uri string, diags []diagnostic) error {
+	if diags == nil {
+		diags = []diagnostic{}
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diags})
+}
+
+// line повертає рядок документа та позицію курсора в ньому в байтах
+func (s *Server) line(uri string, pos position) (string, int) {
+	lines := strings.Split(s.docs[uri], "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return "", 0
+	}
+	line := strings.TrimSuffix(lines[pos.Line], "\r")
+	return line, byteOffset(line, pos.Character)
+}
+
+// diagnose перевіряє документ окремим парсером, щоб не змінювати стан сервера
+func diagnose(text string, scripts fs.FS) []diagnostic {
+	lines := strings.Split(text, "\n")
+	p := &lang.Parser{Scripts: scripts}
+
+	var res []diagnostic
+	for _, e := range p.Check(strings.NewReader(text)) {
+		line := max(e.Line-1, 0)
+		var src string
+		if line < len(lines) {
+			src = strings.TrimSuffix(lines[line], "\r")
+		}
+
+		start, end := len(src)-len(strings.TrimLeft(src, " \t")), len(src)
+		if e.Column > 0 {
+			start, end = e.Column-1, min(e.Column-1+e.Length, len(src))
+		}
+		severity := severityError
+		if e.Warning {
+			severity = severityWarning
+		}
+		res = append(res, diagnostic{
+			Range: lspRange{
+				Start: position{line, utf16Len(src[:min(start, len(src))])},
+				End:   position{line, utf16Len(src[:end])},
+			},
+			Severity: severity,
+			Source:   "painter",
+			Message:  e.Err.Error(),
+		})
+	}
+	return res
+}
+
+type completionItem struct {
+	Label         string `json:"label"`
+	Kind          int    `json:"kind"`
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+	InsertText    string `json:"insertText,omitempty"`
+}
+
+// Види елементів доповнення LSP
+const (
+	kindProperty = 10
+	kindValue    = 12
+	kindKeyword  = 14
+)
+
+// complete пропонує назви команд на початку команди, а далі - іменовані аргументи та назви функцій згладжування
+func complete(line string, col int) []completionItem {
+	fields := strings.Fields(currentCommand(line[:col]))
+	atCommand := len(fields) == 0 || (len(fields) == 1 && !strings.HasSuffix(line[:col], " "))
+
+	var res []completionItem
+	if atCommand {
+		for _, c := range lang.Commands {
+			res = append(res, completionItem{Label: c.Name, Kind: kindKeyword, Detail: c.Usage, Documentation: c.Doc})
+		}
+		return res
+	}
+
+	for _, param := range lang.CommandParams(fields[0]) {
+		res = append(res, completionItem{
+			Label: param + "=", Kind: kindProperty, Detail: fields[0] + " argument", InsertText: param + "=",
+		})
+	}
+	if fields[0] == "animate" {
+		var names []string
+		for name := range painter.Easings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			res = append(res, completionItem{Label: name, Kind: kindValue, Detail: "easing"})
+		}
+	}
+	return res
+}
+
+// hover повертає опис команди або аргументу під курсором
+func hover(line string, col int) any {
+	start := strings.LastIndexAny(line[:col], " \t;{}") + 1
+	end := col + strings.IndexAny(line[col:]+" ", " \t;{}")
+	word := line[start:end]
+	command := ""
+	if fields := strings.Fields(currentCommand(line[:start])); len(fields) > 0 {
+		command = fields[0]
+	} else {
+		command = word
+	}
+
+	var text string
+	if name, _, ok := strings.Cut(word, "="); ok && command != word {
+		for i, param := range lang.CommandParams(command) {
+			if param == name {
+				text = fmt.Sprintf("`%s` — argument %d of `%s`", name, i+1, command)
+			}
+		}
+	} else if command == word {
+		for _, c := range lang.Commands {
+			if c.Name == word {
+				text = fmt.Sprintf("```\n%s\n```\n%s", c.Usage, c.Doc)
+			}
+		}
+	}
+	if text == "" {
+		return nil
+	}
+	return map[string]any{"contents": map[string]string{"kind": "markdown", "value": text}}
+}
+
+// currentCommand повертає частину рядка після останнього роздільника команд
+func currentCommand(prefix string) string {
+	return prefix[strings.LastIndexAny(prefix, ";{}")+1:]
+}
+
+// byteOffset перетворює позицію у кодових одиницях UTF-16, які використовує LSP, на зміщення в байтах
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// utf16Len повертає довжину рядка в кодових одиницях UTF-16
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
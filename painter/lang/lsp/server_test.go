@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// session передає серверу повідомлення клієнта та повертає всі повідомлення сервера
+func session(t *testing.T, requests ...string) []map[string]any {
+	t.Helper()
+	var in bytes.Buffer
+	for _, r := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(r), r)
+	}
+
+	var out bytes.Buffer
+	if err := (&Server{}).Serve(&in, &out); err != nil {
+		t.Fatalf("Serve error: %v", err)
+	}
+
+	var res []map[string]any
+	r := bufio.NewReader(&out)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err != nil {
+			break
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("Invalid message %s: %v", body, err)
+		}
+		res = append(res, msg)
+	}
+	return res
+}
+
+func didOpen(text string) string {
+	params, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": "file:///a.painter", "text": text}})
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":%s}`, params)
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	msgs := session(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		didOpen("white\nfigure 0.5 1.5\n  bogus 1\ntext 0.1 0.1 0.1 \"ї\"; circle 1.5 0.5 0.1\nblit logo 0.1 0.1"),
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+	if len(msgs) != 3 {
+		t.Fatalf("Expected 3 messages, got %d: %v", len(msgs), msgs)
+	}
+
+	caps := msgs[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	if caps["hoverProvider"] != true || caps["textDocumentSync"] != float64(1) {
+		t.Errorf("Unexpected capabilities: %v", caps)
+	}
+	if _, ok := msgs[2]["result"]; !ok || msgs[2]["result"] != nil {
+		t.Errorf("Expected null result for shutdown, got %v", msgs[2])
+	}
+
+	if msgs[1]["method"] != "textDocument/publishDiagnostics" {
+		t.Fatalf("Expected diagnostics, got %v", msgs[1])
+	}
+	diags := msgs[1]["params"].(map[string]any)["diagnostics"].([]any)
+	want := []struct {
+		line, start, end float64
+		severity         float64
+		message          string
+	}{
+		{0, 0, 5, severityWarning, "script has no update command"},
+		{1, 11, 14, severityError, "y value 1.5 out of range"},
+		{2, 2, 9, severityError, "unknown command: bogus"},
+		{3, 29, 32, severityError, "x value 1.5 out of range"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i, w := range want {
+		d := diags[i].(map[string]any)
+		r := d["range"].(map[string]any)
+		start, end := r["start"].(map[string]any), r["end"].(map[string]any)
+		if start["line"] != w.line || start["character"] != w.start || end["character"] != w.end {
+			t.Errorf("Diagnostic %d has range %v, expected line %v %v-%v", i, r, w.line, w.start, w.end)
+		}
+		if d["severity"] != w.severity {
+			t.Errorf("Diagnostic %d has severity %v, expected %v", i, d["severity"], w.severity)
+		}
+		if msg := d["message"].(string); !strings.Contains(msg, w.message) {
+			t.Errorf("Diagnostic %d: expected %q, got %q", i, w.message, msg)
+		}
+	}
+}
+
+func TestServer_CompletionAndHover(t *testing.T) {
+	request := func(id int, method string, line, character int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"%s","params":{"textDocument":{"uri":"file:///a.painter"},"position":{"line":%d,"character":%d}}}`,
+			id, method, line, character)
+	}
+	msgs := session(t,
+		didOpen("fig\nanimate 0 \ncircle x=0.5"),
+		request(1, "textDocument/completion", 0, 3),
+		request(2, "textDocument/completion", 1, 10),
+		request(3, "textDocument/hover", 2, 2),
+		request(4, "textDocument/hover", 2, 8),
+		`{"jsonrpc":"2.0","id":5,"method":"unknown"}`,
+	)
+	if len(msgs) != 6 {
+		t.Fatalf("Expected 6 messages, got %d: %v", len(msgs), msgs)
+	}
+
+	labels := func(msg map[string]any) string {
+		var res []string
+		for _, item := range msg["result"].([]any) {
+			res = append(res, item.(map[string]any)["label"].(string))
+		}
+		return strings.Join(res, " ")
+	}
+	if got := labels(msgs[1]); !strings.Contains(got, "figure") || !strings.Contains(got, "repeat") {
+		t.Errorf("Expected command names, got %s", got)
+	}
+	if got := labels(msgs[2]); !strings.Contains(got, "target= x= y= duration= easing=") || !strings.Contains(got, "bounce") {
+		t.Errorf("Expected animate arguments and easings, got %s", got)
+	}
+
+	hoverText := func(msg map[string]any) string {
+		return msg["result"].(map[string]any)["contents"].(map[string]any)["value"].(string)
+	}
+	if got := hoverText(msgs[3]); !strings.Contains(got, "circle <x> <y> <r>") {
+		t.Errorf("Expected circle usage, got %q", got)
+	}
+	if got := hoverText(msgs[4]); !strings.Contains(got, "argument 1 of `circle`") {
+		t.Errorf("Expected argument description, got %q", got)
+	}
+	if code := msgs[5]["error"].(map[string]any)["code"]; code != float64(codeMethodNotFound) {
+		t.Errorf("Expected method not found error, got %v", msgs[5])
+	}
+}
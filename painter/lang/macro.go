@@ -0,0 +1,161 @@
+package lang
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"strings"
+)
+
+// maxDepth обмежує вкладеність викликів макросів та команд include, щоб рекурсія не виконувалась нескінченно
+const maxDepth = 32
+
+// macro макрос, визначений командою macro
+type macro struct {
+	params []string
+	body   []*statement
+}
+
+// defineMacro зберігає макрос:
+//
+//	macro <name>(<param>, ...) {
+//	  ...
+//	}
+//
+// Макроси зберігаються між скриптами, повторне визначення замінює попереднє.
+func (p *Parser) defineMacro(header []field, body []*statement) error {
+	if len(header) < 2 {
+		return fmt.Errorf("invalid macro command format: expected macro <name>(<params>) {")
+	}
+	name, args, ok := splitCall(strings.ReplaceAll(joinFields(header[1:]), " ", ""))
+	if !ok || !isIdentifier(name) {
+		return fmt.Errorf("invalid macro command format: expected macro <name>(<params>) {")
+	}
+
+	m := &macro{body: body}
+	for _, param := range args {
+		if !isIdentifier(param) {
+			return fmt.Errorf("invalid macro parameter %s", param)
+		}
+		for _, prev := range m.params {
+			if prev == param {
+				return fmt.Errorf("duplicate macro parameter %s", param)
+			}
+		}
+		m.params = append(m.params, param)
+	}
+
+	if p.macros == nil {
+		p.macros = make(map[string]*macro)
+	}
+	p.macros[name] = m
+	return nil
+}
+
+// call виконує макрос:
+//
+//	<name>(<expr>, ...)
+//
+// Аргументи обчислюються у середовищі виклику. Тіло макроса бачить змінні скрипта та параметри, а змінні,
+// задані всередині макроса командою let, після виклику не зберігаються.
+func (p *Parser) call(s *script, name string, args []string) error {
+	m, ok := p.macros[name]
+	if !ok {
+		return fmt.Errorf("unknown macro: %s", name)
+	}
+	if len(args) != len(m.params) {
+		return fmt.Errorf("macro %s expects %d arguments, got %d", name, len(m.params), len(args))
+	}
+
+	vars := maps.Clone(s.vars)
+	for i, arg := range args {
+		v, err := evaluate(arg, s.vars)
+		if err != nil {
+			return fmt.Errorf("macro %s: %v", name, err)
+		}
+		vars[m.params[i]] = v
+	}
+
+	global := s.vars
+	s.vars = vars
+	defer func() { s.vars = global }()
+	return p.nested(s, func() error {
+		if err := p.run(s, m.body); err != nil {
+			return fmt.Errorf("macro %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// include виконує скрипт із каталогу Scripts:
+//
+//	include <file>
+//
+// Включений скрипт виконується у тому ж середовищі, тому може задавати змінні, макроси та фігури.
+func (p *Parser) include(s *script, fields []string) error {
+	if len(fields) != 2 {
+		return fmt.Errorf("invalid include command format")
+	}
+	if p.Scripts == nil {
+		return fmt.Errorf("include is not available: script directory is not configured")
+	}
+
+	name := fields[1]
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid script name: %s", name)
+	}
+	f, err := p.Scripts.Open(name)
+	if err != nil {
+		return fmt.Errorf("include %s: %v", name, err)
+	}
+	defer f.Close()
+
+	block, err := readScript(f)
+	if err != nil {
+		return fmt.Errorf("include %s: %v", name, err)
+	}
+	return p.nested(s, func() error {
+		if err := p.run(s, block); err != nil {
+			return fmt.Errorf("include %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// nested виконує f, збільшуючи глибину вкладеності скрипта
+func (p *Parser) nested(s *script, f func() error) error {
+	if s.depth >= maxDepth {
+		return fmt.Errorf("too deep nesting of macros and includes: limit is %d", maxDepth)
+	}
+	s.depth++
+	defer func() { s.depth-- }()
+	return f()
+}
+
+// splitCall розбирає запис виклику name(a, b) на ім'я та аргументи, розділені комами поза дужками
+func splitCall(text string) (name string, args []string, ok bool) {
+	open := strings.IndexByte(text, '(')
+	if open <= 0 || !strings.HasSuffix(text, ")") {
+		return "", nil, false
+	}
+	name, inner := text[:open], text[open+1:len(text)-1]
+	if strings.TrimSpace(inner) == "" {
+		return name, nil, true
+	}
+
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(inner[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return name, append(args, strings.TrimSpace(inner[start:])), true
+}
@@ -0,0 +1,104 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+func TestParser_ParseMacro(t *testing.T) {
+	parser := &Parser{}
+	_, err := parser.Parse(strings.NewReader(`let size = 0.1
+macro dot(x, y) {
+  let r = $size / 2
+  circle $x $y $r
+}`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	// Макроси доступні в наступних скриптах
+	ops, err := parser.Parse(strings.NewReader(`let size = 0.2
+repeat 2 i {
+  dot(0.2 + $i * 0.5, (0.5))
+}
+circle 0.5 0.5 $size`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 operations, but got %d", len(ops))
+	}
+	for i, op := range ops[:2] {
+		circle := op.(painter.EllipseOperation)
+		if circle.X != scale(0.2+float64(i)*0.5) || circle.Y != scale(0.5) || circle.RX != scale(0.1) {
+			t.Errorf("Circle %d has incorrect parameters: %+v", i, circle)
+		}
+	}
+	if circle := ops[2].(painter.EllipseOperation); circle.RX != scale(0.2) {
+		t.Errorf("Expected variables of the macro not to leak, got %+v", circle)
+	}
+}
+
+func TestParser_ParseInclude(t *testing.T) {
+	parser := &Parser{Scripts: fstest.MapFS{
+		"preamble.txt": {Data: []byte("reset\nwhite\nmacro mark() {\n  figure 0.5 0.5\n}")},
+		"loop.txt":     {Data: []byte("include loop.txt")},
+	}}
+
+	ops, err := parser.Parse(strings.NewReader("include preamble.txt\nmark()\nupdate"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 4 {
+		t.Fatalf("Expected 4 operations, but got %d", len(ops))
+	}
+	if _, ok := ops[2].(*painter.FigureOperation); !ok {
+		t.Errorf("Expected figure from included macro, got %T", ops[2])
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"include missing.txt", "include missing.txt"},
+		{"include ../secret.txt", "invalid script name"},
+		{"include loop.txt", "too deep nesting"},
+		{"unknown()", "unknown macro: unknown"},
+		{"mark(1)", "macro mark expects 0 arguments, got 1"},
+		{"macro bad(x, x) {\n}", "duplicate macro parameter x"},
+		{"macro bad {\n}", "invalid macro command format"},
+		{"macro self() {\nself()\n}\nself()", "too deep nesting"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+
+	if _, err := (&Parser{}).Parse(strings.NewReader("include preamble.txt")); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected include without script directory to fail, got %v", err)
+	}
+}
+
+func TestParser_MacroFanOut(t *testing.T) {
+	// Кожен макрос викликає наступний двічі, тому без спільного обмеження скрипт виконав би 2^30 викликів
+	var b strings.Builder
+	b.WriteString("macro m30() {\nlet x = 1\n}\n")
+	for i := 29; i >= 0; i-- {
+		fmt.Fprintf(&b, "macro m%d() {\nm%d()\nm%d()\n}\n", i, i+1, i+1)
+	}
+	b.WriteString("m0()\nupdate")
+
+	start := time.Now()
+	_, err := (&Parser{}).Parse(strings.NewReader(b.String()))
+	if err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+	if n := strings.Count(err.Error(), "too many steps"); n != 1 {
+		t.Errorf("Expected the step limit to be reported once, got %d times", n)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Expected the script to stop early, took %v", d)
+	}
+}
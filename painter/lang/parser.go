@@ -1,41 +1,87 @@
 package lang
 
 import (
-	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"image"
+	"image/color"
 	"io"
+	"io/fs"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/dk872/architecture-lab3/painter"
 )
 
 // Parser обробляє вхідні дані та генерує відповідні операції.
 type Parser struct {
+	Assets   *Assets // Зображення, доступні для команди blit
+	Scripts  fs.FS   // Каталог скриптів для команди include; якщо не заданий, include недоступна
+	Size     int     // Розмір квадратного полотна у пікселях; 0 означає DefaultCanvasSize
+	MaxLines int     // Максимальна кількість рядків скрипта; 0 означає без обмеження
+
+	mu sync.Mutex // Захищає стан парсера від одночасного доступу з обробників та циклу подій
+
+	parserState
+}
+
+// parserState стан, який змінюють команди скриптів. Він відновлюється, якщо скрипт відхилено через помилки.
+type parserState struct {
 	currentBgColor   painter.Operation          // Поточний фон
+	bgColor          color.Color                // Колір поточного фону
 	currentRect      *painter.RectOperation     // Поточний прямокутник
+	shapeOperations  []painter.Operation        // Операції малювання простих фігур (кола, лінії, многокутники)
 	updateOperation  painter.Operation          // Операція оновлення
 	figureOperations []*painter.FigureOperation // Операції фігур
-	moveOperations   []painter.Operation        // Операції руху
+	figureKeys       []figureKey                // Положення фігур за скриптами; цикл змінює самі фігури без блокування
+	moveOperations   []painter.Operation        // Операції руху та трансформації фігур
+	animations       []painter.Operation        // Анімації, запущені поточним скриптом
+	stopAnimations   bool                       // Поточний скрипт скинув сцену, тому запущені анімації зупиняються
+
+	shapes   map[string]painter.FigureShape // Фігури, визначені командою define; не скидаються командою reset
+	macros   map[string]*macro              // Макроси, визначені командою macro; не скидаються командою reset
+	defining *definition                    // Блок define, який зараз виконується
+}
+
+// saveState повертає копію стану, яку не змінюють команди наступного скрипта. Зрізи лише доповнюються, тому
+// достатньо зберегти їх заголовки, а словники та положення фігур, які змінюють команди руху, копіюються.
+func (p *Parser) saveState() parserState {
+	saved := p.parserState
+	saved.shapes, saved.macros = maps.Clone(p.shapes), maps.Clone(p.macros)
+	saved.figureKeys = slices.Clone(p.figureKeys)
+	return saved
 }
 
 // clearOperations очищає операції оновлення та руху
 func (p *Parser) clearOperations() {
 	p.updateOperation = nil
 	p.moveOperations = nil
+	p.animations = nil
+	p.stopAnimations = false
 }
 
 // getAllOperations повертає всі операції, зібрані парсером
 func (p *Parser) getAllOperations() []painter.Operation {
 	var res []painter.Operation
 
-	// Додавання поточного фону, прямокутника, операцій руху, фігур та оновлення
+	// Додавання зупинки анімацій, поточного фону, прямокутника, операцій руху, фігур та оновлення
+	if p.stopAnimations {
+		res = append(res, painter.StopAnimationsOp)
+	}
 	if p.currentBgColor != nil {
 		res = append(res, p.currentBgColor)
 	}
 	if p.currentRect != nil {
 		res = append(res, p.currentRect)
 	}
+	if len(p.shapeOperations) != 0 {
+		res = append(res, p.shapeOperations...)
+	}
 	if len(p.moveOperations) != 0 {
 		res = append(res, p.moveOperations...)
 	}
@@ -47,46 +93,122 @@ func (p *Parser) getAllOperations() []painter.Operation {
 	if p.updateOperation != nil {
 		res = append(res, p.updateOperation)
 	}
+	if len(p.animations) != 0 {
+		res = append(res, p.animations...)
+	}
 	return res
 }
 
-// Parse зчитує вхідні дані та обробляє команди, створюючи відповідні операції
+// Parse зчитує скрипт та виконує його команди, створюючи відповідні операції. Окрім команд малювання, скрипт
+// може містити змінні, вирази, цикли та умови (див. exec).
 func (p *Parser) Parse(in io.Reader) ([]painter.Operation, error) {
+	ops, _, err := p.ParseWithWarnings(in)
+	return ops, err
+}
+
+// ParseWithWarnings працює так само, як Parse, але додатково повертає попередження про підозрілі конструкції
+// скрипта (див. lint).
+func (p *Parser) ParseWithWarnings(in io.Reader) ([]painter.Operation, []*ScriptError, error) {
+	return p.parseScript(in, false, nil)
+}
+
+// parseScript виконує скрипт. Для рядка потоку команд (line) не перевіряється наявність update, бо в потоці
+// оновлення зазвичай надсилається окремим рядком. Якщо скрипт прийнято, accepted отримує його операції ще під
+// блокуванням парсера, тому скрипти записуються в журнал і надсилаються в цикл у тому ж порядку, в якому
+// змінили стан.
+func (p *Parser) parseScript(in io.Reader, line bool, accepted func([]painter.Operation)) ([]painter.Operation, []*ScriptError, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.clearOperations()
+	p.defining = nil
 
 	var res []painter.Operation
 
-	scanner := bufio.NewScanner(in)
-	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text()) // Розділяє рядок на поля
-		if len(fields) == 0 {
-			continue
-		}
-
-		err := p.parse(fields) // Обробка кожної команди
-		if err != nil {
-			return nil, err
-		}
+	// Синтаксичні помилки не зупиняють перевірку решти команд, тому всі помилки повертаються разом. Скрипт з
+	// помилками відхиляється повністю: стан повертається до того, яким він був перед скриптом.
+	saved := p.saveState()
+	s := &script{vars: make(map[string]float64)}
+	block, err := p.readScript(in)
+	err = errors.Join(err, p.run(s, block))
+	if !line {
+		p.lintScript(s)
+	}
+	if err != nil {
+		p.parserState = saved
+		return nil, s.warnings, err
 	}
 
 	res = append(res, p.getAllOperations()...)
-	return res, nil
+	if accepted != nil {
+		accepted(res)
+	}
+	return res, s.warnings, nil
+}
+
+// ErrTooManyLines помилка скрипта, який довший за Parser.MaxLines рядків.
+var ErrTooManyLines = errors.New("too many lines")
+
+// readScript зчитує скрипт, перевіряючи обмеження кількості рядків. Занадто довгий скрипт не виконується взагалі.
+func (p *Parser) readScript(in io.Reader) ([]*statement, error) {
+	if p.MaxLines <= 0 {
+		return readScript(in)
+	}
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		lines++
+	}
+	if lines > p.MaxLines {
+		return nil, &ScriptError{Line: p.MaxLines + 1, Err: fmt.Errorf("%w: scripts are limited to %d lines", ErrTooManyLines, p.MaxLines)}
+	}
+	return readScript(bytes.NewReader(src))
 }
 
+// DefaultCanvasSize розмір полотна у пікселях, якщо Parser.Size не задано.
+const DefaultCanvasSize = 800
+
 // scale змінює користувацькі координати на ті, з якими працює програма
-func scale(value float64) float64 {
-	const canvasSize = 800
-	return value * canvasSize
+func (p *Parser) scale(value float64) float64 {
+	return value * float64(p.canvasSize())
+}
+
+func (p *Parser) canvasSize() int {
+	if p.Size == 0 {
+		return DefaultCanvasSize
+	}
+	return p.Size
+}
+
+// errOutOfRange помилка нормалізованого значення поза межами полотна
+var errOutOfRange = errors.New("out of range [0.0 - 1.0]")
+
+// errNotFinite помилка числа, яке не можна намалювати: NaN або нескінченності
+var errNotFinite = errors.New("not a finite number")
+
+// parseFloat отримує скінченне число. strconv.ParseFloat приймає також NaN та Inf, які проходять перевірки меж.
+func parseFloat(raw string) (float64, error) {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%s is %w", raw, errNotFinite)
+	}
+	return v, nil
 }
 
 // parseCoordinates отримує координати та перевіряє правильність їх введення: мають бути від 0 до 1
 func parseCoordinates(raw string, name string) (float64, error) {
-	value, err := strconv.ParseFloat(raw, 64)
+	value, err := parseFloat(raw)
 	if err != nil {
 		return 0, fmt.Errorf("invalid %s value: %v", name, err)
 	}
 	if value < 0.0 || value > 1.0 {
-		return 0, fmt.Errorf("%s value %.2f out of range [0.0 - 1.0]", name, value)
+		return 0, fmt.Errorf("%s value %.2f %w", name, value, errOutOfRange)
 	}
 	return value, nil
 }
@@ -98,11 +220,24 @@ func (p *Parser) parse(fields []string) error {
 	switch command {
 	case "white":
 		p.currentBgColor = painter.OperationFunc(painter.WhiteFill)
+		p.bgColor = color.White
 	case "green":
 		p.currentBgColor = painter.OperationFunc(painter.GreenFill)
+		p.bgColor = color.RGBA{G: 0xff, A: 0xff}
+	case "gradient":
+		g, err := p.parseGradient(fields[1:])
+		if err != nil {
+			return err
+		}
+		p.currentBgColor = painter.GradientFillOperation{Gradient: g}
+		p.bgColor = nil
 	case "update":
 		p.updateOperation = painter.UpdateOp
 	case "bgrect":
+		fields, st, err := parseStyle(fields)
+		if err != nil {
+			return err
+		}
 		if len(fields) != 5 {
 			return fmt.Errorf("invalid bgrect command format")
 		}
@@ -124,23 +259,22 @@ func (p *Parser) parse(fields []string) error {
 			return err
 		}
 
-		p.currentRect = &painter.RectOperation{X1: scale(X1), Y1: scale(Y1), X2: scale(X2), Y2: scale(Y2)}
-	case "figure":
-		if len(fields) != 3 {
-			return fmt.Errorf("invalid figure command format")
+		p.currentRect = &painter.RectOperation{
+			X1: p.scale(X1), Y1: p.scale(Y1), X2: p.scale(X2), Y2: p.scale(Y2), Color: st.color, Style: st.Style,
 		}
-
-		X, err := parseCoordinates(fields[1], "X")
+	case "figure":
+		fig, err := p.parseFigure(fields)
 		if err != nil {
 			return err
 		}
-		Y, err := parseCoordinates(fields[2], "Y")
+		p.figureOperations = append(p.figureOperations, fig)
+		p.figureKeys = append(p.figureKeys, keyOf(fig))
+	case "rotate", "scale":
+		transformOp, err := p.parseTransform(fields)
 		if err != nil {
 			return err
 		}
-
-		fig := &painter.FigureOperation{X: scale(X), Y: scale(Y)}
-		p.figureOperations = append(p.figureOperations, fig)
+		p.moveOperations = append(p.moveOperations, transformOp)
 	case "move":
 		if len(fields) != 3 {
 			return fmt.Errorf("invalid move command format")
@@ -155,8 +289,66 @@ func (p *Parser) parse(fields []string) error {
 			return err
 		}
 
-		moveOp := &painter.MoveFiguresOperation{X: scale(X), Y: scale(Y), Figures: &p.figureOperations}
+		moveOp := &painter.MoveFiguresOperation{X: p.scale(X), Y: p.scale(Y), Figures: &p.figureOperations}
 		p.moveOperations = append(p.moveOperations, moveOp)
+		p.updateKeys("all", func(k *figureKey) { k.x, k.y = moveOp.X, moveOp.Y })
+	case "circle", "ellipse", "line", "polygon":
+		shape, err := p.parseShape(fields)
+		if err != nil {
+			return err
+		}
+		p.shapeOperations = append(p.shapeOperations, shape)
+	case "text":
+		text, err := p.parseText(fields)
+		if err != nil {
+			return err
+		}
+		p.shapeOperations = append(p.shapeOperations, text)
+	case "blit":
+		blit, err := p.parseBlit(fields)
+		if err != nil {
+			return err
+		}
+		p.shapeOperations = append(p.shapeOperations, blit)
+	case "animate":
+		if len(fields) != 5 && len(fields) != 6 {
+			return fmt.Errorf("invalid animate command format")
+		}
+
+		figures, err := p.targetFigures(fields[1])
+		if err != nil {
+			return err
+		}
+		X, err := parseCoordinates(fields[2], "X")
+		if err != nil {
+			return err
+		}
+		Y, err := parseCoordinates(fields[3], "Y")
+		if err != nil {
+			return err
+		}
+		duration, err := parseDuration(fields[4])
+		if err != nil {
+			return err
+		}
+		easing := painter.Linear
+		if len(fields) == 6 {
+			var ok bool
+			if easing, ok = painter.Easings[fields[5]]; !ok {
+				return fmt.Errorf("unknown easing: %s", fields[5])
+			}
+		}
+
+		animateOp := &painter.AnimateOperation{
+			Figures:  figures,
+			X:        p.scale(X),
+			Y:        p.scale(Y),
+			Duration: duration,
+			Easing:   easing,
+			Frame:    p.frame,
+		}
+		p.animations = append(p.animations, animateOp)
+		p.updateKeys(fields[1], func(k *figureKey) { k.x, k.y = animateOp.X, animateOp.Y })
 	case "reset":
 		p.resetState()
 	default:
@@ -166,11 +358,120 @@ func (p *Parser) parse(fields []string) error {
 	return nil
 }
 
+// targetFigures повертає фігури, на які посилається команда: "all" означає всі фігури, а число — номер фігури
+// у порядку створення, починаючи з 0
+func (p *Parser) targetFigures(target string) ([]*painter.FigureOperation, error) {
+	if target == "all" {
+		return append([]*painter.FigureOperation(nil), p.figureOperations...), nil
+	}
+
+	i, err := strconv.Atoi(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %s: expected figure number or all", target)
+	}
+	if i < 0 || i >= len(p.figureOperations) {
+		return nil, fmt.Errorf("figure %d does not exist", i)
+	}
+	return p.figureOperations[i : i+1], nil
+}
+
+// updateKeys змінює збережені положення фігур, на які посилається target, так, як їх змінить команда в циклі
+func (p *Parser) updateKeys(target string, update func(k *figureKey)) {
+	if target == "all" {
+		for i := range p.figureKeys {
+			update(&p.figureKeys[i])
+		}
+		return
+	}
+	if i, err := strconv.Atoi(target); err == nil && i >= 0 && i < len(p.figureKeys) {
+		update(&p.figureKeys[i])
+	}
+}
+
+// parseDuration отримує тривалість у секундах (наприклад, 1.5) або у форматі time.Duration (наприклад, 300ms)
+func parseDuration(raw string) (time.Duration, error) {
+	if seconds, err := parseFloat(raw); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("duration %s must not be negative", raw)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value: %v", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %s must not be negative", raw)
+	}
+	return d, nil
+}
+
+// frame повертає операції, які перемальовують поточну сцену для кадру анімації
+func (p *Parser) frame() painter.Operation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var res painter.OperationList
+	if p.currentBgColor != nil {
+		res = append(res, p.currentBgColor)
+	}
+	if p.currentRect != nil {
+		res = append(res, p.currentRect)
+	}
+	res = append(res, p.shapeOperations...)
+	for _, figure := range p.figureOperations {
+		res = append(res, figure)
+	}
+	return append(res, painter.UpdateOp)
+}
+
 // resetState скидає всі зібрані операції та налаштовує початковий стан
 func (p *Parser) resetState() {
 	p.currentBgColor = painter.OperationFunc(painter.ResetOperation)
+	p.bgColor = color.Black
 	p.currentRect = nil
+	p.shapeOperations = nil
 	p.updateOperation = nil
 	p.figureOperations = nil
+	p.figureKeys = nil
 	p.moveOperations = nil
+	p.animations = nil
+	p.stopAnimations = true
+}
+
+// Scene повертає знімок поточної сцени: фон, прямокутник, прості фігури та всі фігури. Щоб отримати актуальні координати фігур,
+// його потрібно викликати з циклу подій, де виконуються операції руху.
+func (p *Parser) Scene() painter.Scene {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	scene := painter.Scene{Background: p.bgColor, Size: image.Pt(p.canvasSize(), p.canvasSize())}
+	if _, ok := p.currentBgColor.(painter.SVGElement); ok {
+		scene.Operations = append(scene.Operations, p.currentBgColor)
+	}
+	if p.currentRect != nil {
+		scene.Operations = append(scene.Operations, *p.currentRect)
+	}
+	scene.Operations = append(scene.Operations, p.shapeOperations...)
+	for _, figure := range p.figureOperations {
+		scene.Operations = append(scene.Operations, *figure)
+	}
+	return scene
+}
+
+// objects повертає кількість об'єктів сцени, як у Scene. Фігури не розіменовуються, бо цикл подій змінює їх без
+// блокування парсера, тому метод можна викликати з будь-якої горутини.
+func (p *Parser) objects() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.shapeOperations) + len(p.figureOperations)
+	if _, ok := p.currentBgColor.(painter.SVGElement); ok {
+		n++
+	}
+	if p.currentRect != nil {
+		n++
+	}
+	return n
 }
@@ -1,13 +1,57 @@
 package lang
 
 import (
+	"errors"
+	"image/color"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
 )
 
+// scale перетворює нормалізовані координати на пікселі полотна розміру за замовчуванням
+func scale(value float64) float64 {
+	return value * DefaultCanvasSize
+}
+
+func TestParser_Size(t *testing.T) {
+	p := &Parser{Size: 200}
+	ops, err := p.Parse(strings.NewReader("figure 0.5 0.25\ncircle 0.1 0.2 0.3\nupdate"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fig := ops[1].(*painter.FigureOperation); fig.X != 100 || fig.Y != 50 {
+		t.Errorf("Expected figure at (100, 50), got (%v, %v)", fig.X, fig.Y)
+	}
+	if circle := ops[0].(painter.EllipseOperation); circle.X != 20 || circle.RX != 60 {
+		t.Errorf("Expected circle scaled to 200 pixels, got %+v", circle)
+	}
+	if size := p.Scene().Size; size.X != 200 || size.Y != 200 {
+		t.Errorf("Expected scene size 200x200, got %v", size)
+	}
+}
+
+func TestParser_MaxLines(t *testing.T) {
+	p := &Parser{MaxLines: 2}
+	if _, err := p.Parse(strings.NewReader("figure 0.1 0.1\nupdate\n")); err != nil {
+		t.Errorf("Unexpected error for script within the limit: %v", err)
+	}
+	before := p.Scene()
+	ops, err := p.Parse(strings.NewReader("white\nfigure 0.5 0.5\nupdate"))
+	if !errors.Is(err, ErrTooManyLines) || ops != nil {
+		t.Fatalf("Expected ErrTooManyLines, got %v", err)
+	}
+	if err.Error() != "line 3: too many lines: scripts are limited to 2 lines" {
+		t.Errorf("Unexpected error message %q", err)
+	}
+	if !reflect.DeepEqual(p.Scene(), before) {
+		t.Errorf("Expected rejected script not to change the scene")
+	}
+}
+
 func TestParser_ParseMultipleCommands(t *testing.T) {
 	input := `white
 bgrect 0.1 0.2 0.3 0.4
@@ -109,14 +153,23 @@ update`
 	if err != nil {
 		t.Fatalf("Error: %v", err)
 	}
-	if len(ops) != 2 {
-		t.Fatalf("Expected 2 operations after reset (reset, update), but got %d", len(ops))
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 operations after reset (stop animations, reset, update), but got %d", len(ops))
 	}
-	if _, ok := ops[0].(painter.OperationFunc); !ok {
-		t.Errorf("Expected OperationFunc (reset), got %T", ops[0])
+	if ops[0] != painter.StopAnimationsOp {
+		t.Errorf("Expected animations to be stopped first, got %T", ops[0])
 	}
-	if reflect.TypeOf(ops[1]).String() != "painter.updateOp" {
-		t.Errorf("Expected updateOp, got %T", ops[1])
+	if _, ok := ops[1].(painter.OperationFunc); !ok {
+		t.Errorf("Expected OperationFunc (reset), got %T", ops[1])
+	}
+	if reflect.TypeOf(ops[2]).String() != "painter.updateOp" {
+		t.Errorf("Expected updateOp, got %T", ops[2])
+	}
+
+	// Анімації зупиняються лише скриптом, який скидає сцену
+	ops, err = parser.Parse(strings.NewReader("update"))
+	if err != nil || len(ops) != 2 || ops[0] == painter.StopAnimationsOp {
+		t.Errorf("Expected animations to keep running after a script without reset, got %v (%v)", ops, err)
 	}
 }
 
@@ -172,3 +225,331 @@ func TestParser_UnknownAndInvalidCommands(t *testing.T) {
 		}
 	})
 }
+
+func TestParser_Scene(t *testing.T) {
+	input := `green
+bgrect 0.1 0.1 0.2 0.2
+figure 0.5 0.5
+figure 0.25 0.25`
+
+	parser := &Parser{}
+	if _, err := parser.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	scene := parser.Scene()
+	if scene.Background != (color.RGBA{G: 0xff, A: 0xff}) {
+		t.Errorf("Unexpected background color: %v", scene.Background)
+	}
+	if len(scene.Operations) != 3 {
+		t.Fatalf("Expected 3 scene operations, got %d", len(scene.Operations))
+	}
+	if _, ok := scene.Operations[0].(painter.RectOperation); !ok {
+		t.Errorf("Expected RectOperation, got %T", scene.Operations[0])
+	}
+	if fig, ok := scene.Operations[2].(painter.FigureOperation); !ok || fig.X != scale(0.25) {
+		t.Errorf("Expected second FigureOperation at 0.25, got %+v", scene.Operations[2])
+	}
+
+	if _, err := parser.Parse(strings.NewReader("reset")); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	scene = parser.Scene()
+	if scene.Background != color.Black || len(scene.Operations) != 0 {
+		t.Errorf("Expected empty black scene after reset, got %+v", scene)
+	}
+}
+
+func TestParser_ParseAnimate(t *testing.T) {
+	input := `figure 0.1 0.1
+figure 0.2 0.2
+animate 1 0.5 0.5 1.5 bounce
+animate all 0.3 0.3 300ms`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 4 {
+		t.Fatalf("Expected 4 operations, but got %d", len(ops))
+	}
+
+	first, ok := ops[2].(*painter.AnimateOperation)
+	if !ok {
+		t.Fatalf("Expected AnimateOperation, got %T", ops[2])
+	}
+	if len(first.Figures) != 1 || first.Figures[0] != ops[1] {
+		t.Errorf("Expected animation of the second figure, got %+v", first.Figures)
+	}
+	if first.X != scale(0.5) || first.Duration != 1500*time.Millisecond {
+		t.Errorf("AnimateOperation has incorrect values: %+v", first)
+	}
+
+	second := ops[3].(*painter.AnimateOperation)
+	if len(second.Figures) != 2 || second.Duration != 300*time.Millisecond {
+		t.Errorf("Expected animation of all figures for 300ms, got %+v", second)
+	}
+	if frame, ok := second.Frame().(painter.OperationList); !ok || len(frame) != 3 {
+		t.Errorf("Expected frame with 2 figures and update, got %+v", second.Frame())
+	}
+
+	t.Run("invalid target", func(t *testing.T) {
+		_, err := parser.Parse(strings.NewReader("animate 5 0.5 0.5 1"))
+		if err == nil || !strings.Contains(err.Error(), "figure 5 does not exist") {
+			t.Errorf("Expected missing figure error, got %v", err)
+		}
+	})
+
+	t.Run("unknown easing", func(t *testing.T) {
+		_, err := parser.Parse(strings.NewReader("animate 0 0.5 0.5 1 wobble"))
+		if err == nil || !strings.Contains(err.Error(), "unknown easing") {
+			t.Errorf("Expected unknown easing error, got %v", err)
+		}
+	})
+}
+
+func TestParser_ParseShapes(t *testing.T) {
+	input := `circle 0.5 0.5 0.1
+ellipse 0.5 0.5 0.2 0.1 #f00
+line 0 0 1 1 0.01 #00ff00
+polygon 0 0 0.5 0 0.5 0.5 0 0.5
+update`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 5 {
+		t.Fatalf("Expected 5 operations, but got %d", len(ops))
+	}
+
+	if circle, ok := ops[0].(painter.EllipseOperation); !ok || circle.RX != scale(0.1) || circle.RY != circle.RX {
+		t.Errorf("Expected circle with radius 0.1, got %+v", ops[0])
+	}
+	if ellipse, ok := ops[1].(painter.EllipseOperation); !ok || ellipse.Color != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("Expected red ellipse, got %+v", ops[1])
+	}
+	if line, ok := ops[2].(painter.LineOperation); !ok || line.Width != scale(0.01) || line.X2 != scale(1) {
+		t.Errorf("Expected line with width 0.01, got %+v", ops[2])
+	}
+	if polygon, ok := ops[3].(painter.PolygonOperation); !ok || len(polygon.Points) != 4 {
+		t.Errorf("Expected polygon with 4 points, got %+v", ops[3])
+	}
+	if len(parser.Scene().Operations) != 4 {
+		t.Errorf("Expected shapes to be part of the scene, got %+v", parser.Scene())
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"circle 0.5 0.5", "invalid circle command format"},
+		{"polygon 0 0 1 1", "expected at least 3 points"},
+		{"line 0 0 1 2", "argument 4 value 2.00 out of range"},
+		{"circle 0.5 0.5 0.1 #zzz", "invalid color #zzz"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseText(t *testing.T) {
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(`text 0.5 0.1 0.05 "Лабораторна \"робота\"" center #f00`))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 1 {
+		t.Fatalf("Expected 1 operation, but got %d", len(ops))
+	}
+
+	text, ok := ops[0].(painter.TextOperation)
+	if !ok {
+		t.Fatalf("Expected TextOperation, got %T", ops[0])
+	}
+	if text.Text != `Лабораторна "робота"` || text.Align != painter.AlignCenter || text.Size != scale(0.05) {
+		t.Errorf("TextOperation has incorrect values: %+v", text)
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{`text 0.5 0.5 0.1 "unterminated`, "unterminated quoted string"},
+		{`text 0.5 0.5 0.1`, "invalid text command format"},
+		{`text 0.5 0.5 0.1 "a" middle`, "unknown text option: middle"},
+		{`text 0 0 0.1 "` + strings.Repeat("a", maxTextLength+1) + `"`, "text is too long"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseGradient(t *testing.T) {
+	input := `gradient linear #fff #000 90
+circle 0.5 0.5 0.25 gradient radial #f00 #00f 0.5 0.5 0.25`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 operations, but got %d", len(ops))
+	}
+
+	bg, ok := ops[0].(painter.GradientFillOperation)
+	if !ok || bg.Gradient.Angle != 90 || bg.Gradient.Radial {
+		t.Errorf("Expected linear gradient background, got %+v", ops[0])
+	}
+	circle, ok := ops[1].(painter.EllipseOperation)
+	if !ok || circle.Gradient == nil || !circle.Gradient.Radial || circle.Gradient.R != scale(0.25) {
+		t.Errorf("Expected circle with radial gradient, got %+v", ops[1])
+	}
+	if scene := parser.Scene(); scene.Background != nil || len(scene.Operations) != 2 {
+		t.Errorf("Expected gradient background to be a scene operation, got %+v", scene)
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"gradient conic #fff #000 0", "unknown gradient type: conic"},
+		{"gradient linear #fff #000", "invalid linear gradient format"},
+		{"circle 0.5 0.5 0.1 gradient radial #fff #000 0.5 0.5", "invalid radial gradient format"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseStyle(t *testing.T) {
+	input := `bgrect 0.1 0.1 0.5 0.5 #ff000080 blend=src
+figure 0.5 0.5 #00f opacity=0.5
+circle 0.5 0.5 0.1 #0f08 opacity=0.25 blend=over`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 operations, but got %d", len(ops))
+	}
+
+	rect := ops[0].(*painter.RectOperation)
+	if rect.Color != (color.RGBA{R: 0x80, A: 0x80}) || rect.Blend != screen.Src {
+		t.Errorf("Expected semi-transparent red rect with src blending, got %+v", rect)
+	}
+	fig := ops[2].(*painter.FigureOperation)
+	if fig.Color != (color.RGBA{B: 0xff, A: 0xff}) || fig.Transparency != 0.5 {
+		t.Errorf("Expected blue figure at 50%% opacity, got %+v", fig)
+	}
+	circle := ops[1].(painter.EllipseOperation)
+	if circle.Color != (color.RGBA{G: 0x88, A: 0x88}) || circle.Transparency != 0.75 || circle.Blend != screen.Over {
+		t.Errorf("Expected semi-transparent green circle, got %+v", circle)
+	}
+	if ops, err := (&Parser{}).Parse(strings.NewReader("circle 0.5 0.5 0.1 opacity=0")); err != nil || ops[0].(painter.EllipseOperation).Transparency != 1 {
+		t.Errorf("Expected opacity=0 to make the circle invisible, got %v %v", ops, err)
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"figure 0.5 0.5 opacity=2", "invalid opacity"},
+		{"bgrect 0 0 1 1 blend=xor", "unknown blend mode: xor"},
+		{"circle 0.5 0.5 0.1 #12345", "invalid color #12345"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseTransforms(t *testing.T) {
+	input := `figure 0.5 0.5 scale=0.5 rot=45 #f00
+figure 0.2 0.2
+rotate all 90
+scale 1 2`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 4 {
+		t.Fatalf("Expected 4 operations, but got %d", len(ops))
+	}
+
+	fig := ops[2].(*painter.FigureOperation)
+	if fig.Scale != 0.5 || fig.Rotation != 45 || fig.Color != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("FigureOperation has incorrect transform: %+v", fig)
+	}
+	if rotate, ok := ops[0].(painter.RotateFiguresOperation); !ok || rotate.Degrees != 90 || len(rotate.Figures) != 2 {
+		t.Errorf("Expected rotation of all figures, got %+v", ops[0])
+	}
+	if sc, ok := ops[1].(painter.ScaleFiguresOperation); !ok || sc.Factor != 2 || sc.Figures[0] != ops[3] {
+		t.Errorf("Expected scaling of the second figure, got %+v", ops[1])
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"figure 0.5 0.5 scale=0", "scale value 0.00 must be positive"},
+		{"figure 0.5 0.5 rot=abc", "invalid angle value"},
+		{"figure 0.5 0.5 size=2", "invalid figure command format"},
+		{"figure 0.5 0.5\nrotate 0", "invalid rotate command format"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseDefine(t *testing.T) {
+	input := `define house {
+  rect -0.1 0 0.1 0.2
+  polygon -0.12 0 0 -0.1 0.12 0 #f00
+}
+figure house 0.5 0.5 #00f
+figure t 0.2 0.2
+figure 0.3 0.3`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 operations, but got %d", len(ops))
+	}
+
+	house := ops[0].(*painter.FigureOperation)
+	if len(house.Shape) != 2 || house.X != 400 || house.Color != (color.RGBA{B: 0xff, A: 0xff}) {
+		t.Fatalf("Expected house figure with 2 parts, got %+v", house)
+	}
+	if house.Shape[0].Points[0] != (painter.Point{X: -80, Y: 0}) || house.Shape[0].Color != nil {
+		t.Errorf("Unexpected rect part: %+v", house.Shape[0])
+	}
+	if house.Shape[1].Color != (color.RGBA{R: 0xff, A: 0xff}) || len(house.Shape[1].Points) != 3 {
+		t.Errorf("Unexpected polygon part: %+v", house.Shape[1])
+	}
+	for _, op := range ops[1:] {
+		if op.(*painter.FigureOperation).Shape != nil {
+			t.Errorf("Expected default figure, got %+v", op)
+		}
+	}
+
+	// Визначення зберігаються між викликами Parse
+	if _, err := parser.Parse(strings.NewReader("figure house 0.1 0.1")); err != nil {
+		t.Errorf("Expected definition to persist, got %v", err)
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"figure boat 0.5 0.5", "unknown figure: boat"},
+		{"define boat {\nrect 0 0 0.1 0.1", "unterminated define boat"},
+		{"define boat {\n}", "figure boat has no parts"},
+		{"define t {\n}", "cannot be redefined"},
+		{"define 1 {\n}", "invalid figure name"},
+		{"define boat\n", "invalid define command format"},
+		{"define boat {\nrect 0 0 2 0.1\n}", "out of range"},
+		{"define boat {\ncircle 0 0 0.1 opacity=0.5\n}", "only color can be set"},
+		{"define boat {\ntext 0 0 0.1\n}", "unknown figure part: text"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package lang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Recordings обробляє запити на запис кадрів, які цикл передає отримувачам:
+//
+//	POST /start?format=gif|png  почати запис анімованого GIF (за замовчуванням) або послідовності PNG
+//	POST /stop                  завершити запис; у відповіді шлях до запису та кількість кадрів
+//
+// Записи зберігаються у каталозі Dir з назвами за часом початку запису. Одночасно може йти лише один запис.
+// Прочитати вдається лише текстури, які підтримують знімки, як текстури ui.Headless; з вікном shiny зупинка запису
+// повертає помилку painter.ErrUnreadableTexture.
+type Recordings struct {
+	Loop   *painter.Loop
+	Parser *Parser // Якщо заданий, поточна сцена стає першим кадром запису, навіть якщо вона не змінюється
+	Dir    string
+
+	mu       sync.Mutex
+	recorder *painter.Recorder
+	file     io.Closer // Файл GIF, який закривається після запису
+	path     string
+}
+
+func (rs *Recordings) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/start":
+		if rs.recorder != nil {
+			http.Error(rw, "recording is already in progress: "+rs.path, http.StatusConflict)
+			return
+		}
+		if err := rs.start(r.URL.Query().Get("format")); err != nil {
+			log.Printf("Failed to start recording: %s", err)
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(rw, rs.path)
+	case "/stop":
+		if rs.recorder == nil {
+			http.Error(rw, "no recording in progress", http.StatusConflict)
+			return
+		}
+		path, frames, err := rs.stop()
+		if err != nil {
+			log.Printf("Failed to finish recording %s: %s", path, err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(rw, "%s\n%d frames\n", path, frames)
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+func (rs *Recordings) start(format string) error {
+	name := filepath.Join(rs.Dir, "recording-"+time.Now().Format("20060102-150405.000"))
+	if err := os.MkdirAll(rs.Dir, 0o755); err != nil {
+		return err
+	}
+
+	switch format {
+	case "", "gif":
+		f, err := os.Create(name + ".gif")
+		if err != nil {
+			return err
+		}
+		rs.recorder, rs.file, rs.path = painter.NewGIFRecorder(f), f, f.Name()
+	case "png":
+		if err := os.Mkdir(name, 0o755); err != nil {
+			return err
+		}
+		rs.recorder, rs.file, rs.path = painter.NewPNGRecorder(name), nil, name
+	default:
+		return fmt.Errorf("unknown recording format %q: expected gif or png", format)
+	}
+
+	rs.Loop.AddReceiver(rs.recorder)
+	if rs.Parser != nil {
+		rs.Loop.Post(rs.Parser.frame())
+	}
+	return nil
+}
+
+func (rs *Recordings) stop() (string, int, error) {
+	rec, file, path := rs.recorder, rs.file, rs.path
+	rs.recorder, rs.file, rs.path = nil, nil, ""
+
+	// Кадри скриптів, надісланих до зупинки, потрапляють у запис: спочатку цикл виконує всі операції, а
+	// RemoveReceiver чекає, поки записувач обробить останній отриманий кадр
+	flush(rs.Loop)
+	rs.Loop.RemoveReceiver(rec)
+	err := rec.Close()
+	if file != nil {
+		err = errors.Join(err, file.Close())
+	}
+	if errors.Is(err, painter.ErrUnreadableTexture) || errors.Is(err, painter.ErrNoFrames) {
+		_ = os.RemoveAll(path) // Порожній запис не потрібен
+	}
+	if dropped := rec.Dropped(); dropped > 0 && err == nil {
+		log.Printf("Recording %s reached the frame limit: %d frames dropped", path, dropped)
+	}
+	return path, rec.Frames(), err
+}
+
+// flush чекає, поки цикл виконає операції, надіслані раніше. Цикл може бути ще не запущений, тому очікування
+// обмежене.
+func flush(loop *painter.Loop) {
+	done := make(chan struct{})
+	loop.Post(painter.OperationFunc(func(screen.Texture) { close(done) }))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+	}
+}
@@ -0,0 +1,100 @@
+package lang
+
+import (
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+func TestRecordings(t *testing.T) {
+	var loop painter.Loop
+	rs := &Recordings{Loop: &loop, Dir: t.TempDir()}
+	request := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rs.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	if rec := request(http.MethodPost, "/stop"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 without recording, got %d", rec.Code)
+	}
+	if rec := request(http.MethodPost, "/start?format=avi"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown format, got %d", rec.Code)
+	}
+	if rec := request(http.MethodGet, "/start"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+
+	rec := request(http.MethodPost, "/start?format=png")
+	path := strings.TrimSpace(rec.Body.String())
+	if rec.Code != http.StatusOK || !strings.HasPrefix(path, rs.Dir) {
+		t.Fatalf("Expected recording to start, got %d %q", rec.Code, path)
+	}
+	if rec := request(http.MethodPost, "/start"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for second recording, got %d", rec.Code)
+	}
+
+	// Цикл не запущений і не передавав кадрів, тому порожній запис видаляється
+	rec = request(http.MethodPost, "/stop")
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "no frames") {
+		t.Errorf("Expected no frames error, got %d %q", rec.Code, rec.Body.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected empty recording to be removed, got %v", err)
+	}
+}
+
+// rgbaScreen створює текстури у пам'яті, вміст яких можна прочитати
+type rgbaScreen struct{ sizeScreen }
+
+func (rgbaScreen) NewTexture(size image.Point) (screen.Texture, error) {
+	return &rgbaTexture{image.NewRGBA(image.Rectangle{Max: size})}, nil
+}
+
+type rgbaTexture struct{ img *image.RGBA }
+
+func (t *rgbaTexture) Release()                                           {}
+func (t *rgbaTexture) Size() image.Point                                  { return t.img.Rect.Size() }
+func (t *rgbaTexture) Bounds() image.Rectangle                            { return t.img.Rect }
+func (t *rgbaTexture) Upload(image.Point, screen.Buffer, image.Rectangle) {}
+func (t *rgbaTexture) Fill(r image.Rectangle, c color.Color, op draw.Op) {
+	draw.Draw(t.img, r, image.NewUniform(c), image.Point{}, op)
+}
+func (t *rgbaTexture) Snapshot() *image.RGBA {
+	res := image.NewRGBA(t.img.Rect)
+	copy(res.Pix, t.img.Pix)
+	return res
+}
+
+func TestRecordings_StaticScene(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+	)
+	if _, err := parser.Parse(strings.NewReader("green\nupdate")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loop.Start(rgbaScreen{})
+	defer loop.StopAndWait()
+
+	rs := &Recordings{Loop: &loop, Parser: &parser, Dir: t.TempDir()}
+	for _, target := range []string{"/start", "/stop"} {
+		rec := httptest.NewRecorder()
+		rs.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %q", target, rec.Code, rec.Body.String())
+		}
+		// Сцена не змінюється, але поточний кадр все одно записується
+		if target == "/stop" && !strings.Contains(rec.Body.String(), "1 frames") {
+			t.Errorf("Expected the current frame to be recorded, got %q", rec.Body.String())
+		}
+	}
+}
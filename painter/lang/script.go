@@ -0,0 +1,356 @@
+package lang
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxSteps обмежує загальну кількість виконаних команд, викликів макросів та ітерацій циклів в одному скрипті,
+// щоб вкладені цикли та макроси, які викликають інші макроси кілька разів, не тримали парсер надто довго
+const maxSteps = 500000
+
+// errTooManySteps помилка скрипта, який перевищив maxSteps; після неї виконання скрипта зупиняється
+var errTooManySteps = fmt.Errorf("too many steps: scripts are limited to %d commands, macro calls and loop iterations", maxSteps)
+
+// statement вузол синтаксичного дерева: команда, а для блоків repeat, if, define та macro - також вкладені команди
+type statement struct {
+	fields   []field
+	line     int
+	end      int    // Рядок, на якому закінчується команда разом з блоком
+	comment  string // Текст коментаря; коментар зберігається як окремий вузол без полів
+	trailing bool   // Коментар стоїть у рядку після команди
+	body     []*statement
+	elseBody []*statement
+	block    bool // Після полів команди йде блок { ... }
+	hasElse  bool
+}
+
+// keyword повертає перше поле рядка, якщо воно не взяте в лапки
+func (st *statement) keyword() string {
+	if len(st.fields) == 0 || st.fields[0].quoted {
+		return ""
+	}
+	return st.fields[0].text
+}
+
+// readScript зчитує скрипт і будує синтаксичне дерево. Синтаксичні помилки не зупиняють розбір, усі вони
+// повертаються разом із деревом, побудованим з коректних команд.
+func readScript(in io.Reader) ([]*statement, error) {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	tokens, errs := lex(string(src))
+	sp := &scriptParser{tokens: tokens, errs: errs}
+	block := sp.parseBlock(nil)
+	return block, errors.Join(sp.errs...)
+}
+
+// scriptParser будує синтаксичне дерево з лексем
+type scriptParser struct {
+	tokens []token
+	pos    int
+	errs   []error
+}
+
+func (sp *scriptParser) peek() (token, bool) {
+	if sp.pos < len(sp.tokens) {
+		return sp.tokens[sp.pos], true
+	}
+	return token{}, false
+}
+
+func (sp *scriptParser) fail(line int, format string, args ...any) {
+	sp.errs = append(sp.errs, &ScriptError{Line: line, Err: fmt.Errorf(format, args...)})
+}
+
+// parseBlock розбирає команди до кінця скрипта або, якщо задано owner, до } цього блоку
+func (sp *scriptParser) parseBlock(owner *statement) []*statement {
+	var block []*statement
+	for {
+		t, ok := sp.peek()
+		switch {
+		case !ok:
+			if owner != nil {
+				sp.fail(owner.line, "unterminated %s: expected }", joinFields(owner.fields))
+			}
+			return block
+		case t.kind == tokenEnd:
+			sp.pos++
+		case t.kind == tokenComment:
+			sp.pos++
+			block = append(block, &statement{comment: t.text, trailing: t.trailing, line: t.line, end: t.line})
+		case t.kind == tokenRBrace:
+			sp.pos++
+			if owner != nil {
+				owner.end = t.line
+				return block
+			}
+			sp.fail(t.line, "unexpected }")
+		case t.kind == tokenLBrace:
+			sp.pos++
+			sp.fail(t.line, "unexpected {")
+			sp.parseBlock(&statement{fields: []field{{text: "{"}}, line: t.line})
+		default:
+			block = append(block, sp.parseStatement())
+		}
+	}
+}
+
+// parseStatement розбирає команду з необов'язковим блоком та гілкою else
+func (sp *scriptParser) parseStatement() *statement {
+	st := &statement{line: sp.tokens[sp.pos].line}
+	st.end = st.line
+	for t, ok := sp.peek(); ok && t.kind == tokenField; t, ok = sp.peek() {
+		st.fields = append(st.fields, t.field)
+		sp.pos++
+	}
+
+	if t, ok := sp.peek(); ok && t.kind == tokenLBrace {
+		sp.pos++
+		st.block = true
+		st.body = sp.parseBlock(st)
+
+		// else має йти одразу після }, у тому ж рядку
+		if e, ok := sp.peek(); ok && e.kind == tokenField && !e.quoted && e.text == "else" {
+			sp.pos++
+			if st.keyword() != "if" {
+				sp.fail(e.line, "unexpected else")
+			}
+			if t, ok := sp.peek(); !ok || t.kind != tokenLBrace {
+				sp.fail(e.line, "expected { after else")
+				return st
+			}
+			sp.pos++
+			st.hasElse = true
+			st.elseBody = sp.parseBlock(st)
+		}
+	}
+	return st
+}
+
+// joinFields з'єднує поля через пробіл
+func joinFields(fields []field) string {
+	texts := make([]string, len(fields))
+	for i, f := range fields {
+		texts[i] = f.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// script стан виконання скрипта: значення змінних та кількість виконаних ітерацій циклів
+type script struct {
+	vars  map[string]float64
+	steps int // Кількість виконаних команд, викликів макросів та ітерацій циклів
+	depth int // Глибина вкладеності викликів макросів та команд include
+
+	commands int               // Кількість виконаних команд малювання
+	warnings []*ScriptError    // Попередження про підозрілі конструкції
+	figures  map[figureKey]int // Номери фігур за формою та положенням для перевірки перекриття (див. lint)
+}
+
+// run виконує послідовність команд. Помилка в одній команді не зупиняє виконання наступних, повертаються
+// всі помилки з номерами рядків.
+func (p *Parser) run(s *script, block []*statement) error {
+	var errs []error
+	for _, st := range block {
+		err := p.exec(s, st)
+		switch err.(type) {
+		case nil:
+		case *ScriptError, interface{ Unwrap() []error }:
+			errs = append(errs, err) // Помилки вкладеного блоку вже містять номери рядків
+		default:
+			errs = append(errs, &ScriptError{Line: st.line, Err: err})
+		}
+		if errors.Is(err, errTooManySteps) {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// exec виконує один рядок скрипта:
+//
+//	let <name> = <expr>
+//	repeat <count> [<name>] { ... }
+//	if <expr> { ... } [else { ... }]
+//	macro <name>(<params>) { ... }
+//	<name>(<args>)
+//	include <file>
+//
+// Змінна циклу repeat набуває значень від 0 до count-1. В інших командах поля, що містять змінні $name або
+// починаються з дужки, обчислюються як вирази (див. evaluate), а поля у лапках залишаються без змін.
+// Аргументи команд можна передавати за іменем (див. namedArguments).
+func (p *Parser) exec(s *script, st *statement) error {
+	if len(st.fields) == 0 {
+		return nil // Коментар
+	}
+	if s.steps++; s.steps > maxSteps {
+		return errTooManySteps
+	}
+	if st.block {
+		header := st.fields
+		switch st.keyword() {
+		case "repeat":
+			return p.repeat(s, header, st.body)
+		case "if":
+			if len(header) < 2 {
+				return fmt.Errorf("invalid if command format")
+			}
+			cond, err := evaluate(joinFields(header[1:]), s.vars)
+			if err != nil {
+				return err
+			}
+			if cond != 0 {
+				return p.run(s, st.body)
+			}
+			return p.run(s, st.elseBody)
+		case "define":
+			return p.define(s, st)
+		case "macro":
+			return p.defineMacro(header, st.body)
+		default:
+			return fmt.Errorf("unexpected block after %s", st.keyword())
+		}
+	}
+
+	switch st.keyword() {
+	case "repeat", "if", "define", "macro":
+		return fmt.Errorf("invalid %s command format: expected { after %s", st.keyword(), joinFields(st.fields))
+	}
+
+	if st.keyword() == "let" {
+		if len(st.fields) < 4 || st.fields[2].text != "=" || !isIdentifier(st.fields[1].text) {
+			return fmt.Errorf("invalid let command format: expected let <name> = <expr>")
+		}
+		v, err := evaluate(joinFields(st.fields[3:]), s.vars)
+		if err != nil {
+			return err
+		}
+		s.vars[st.fields[1].text] = v
+		return nil
+	}
+
+	if name, args, ok := splitCall(st.fields[0].text); ok && !st.fields[0].quoted && len(st.fields) == 1 {
+		return p.call(s, name, args)
+	}
+
+	args := st.fields
+	if p.defining == nil {
+		var err error
+		if args, err = namedArguments(args); err != nil {
+			return err
+		}
+	}
+	fields, err := s.expand(args)
+	if err != nil {
+		return err
+	}
+	if fields[0] == "include" {
+		return p.include(s, fields)
+	}
+	if p.defining != nil {
+		return p.parseDefinition(fields) // Рядок усередині блоку define
+	}
+	if err := p.parse(fields); err != nil { // Обробка кожної команди
+		return err
+	}
+	p.lint(s, st.line, fields)
+	return nil
+}
+
+// repeat виконує тіло циклу задану кількість разів
+func (p *Parser) repeat(s *script, header []field, body []*statement) error {
+	if len(header) != 2 && len(header) != 3 {
+		return fmt.Errorf("invalid repeat command format: expected repeat <count> [<name>] {")
+	}
+	count, err := s.evaluate(header[1])
+	if err != nil {
+		return err
+	}
+	if count < 0 || count != math.Trunc(count) {
+		return fmt.Errorf("repeat count %g must be a non-negative integer", count)
+	}
+	var name string
+	if len(header) == 3 {
+		if name = header[2].text; !isIdentifier(name) {
+			return fmt.Errorf("invalid variable name %s", name)
+		}
+	}
+
+	for i := 0; i < int(count); i++ {
+		if s.steps++; s.steps > maxSteps {
+			return errTooManySteps
+		}
+		if name != "" {
+			s.vars[name] = float64(i)
+		}
+		if err := p.run(s, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// define виконує блок визначення фігури, рядки якого можуть містити змінні та цикли
+func (p *Parser) define(s *script, st *statement) error {
+	if p.defining != nil {
+		return fmt.Errorf("nested define is not allowed")
+	}
+	fields, err := s.expand(st.fields)
+	if err != nil {
+		return err
+	}
+	if err := p.startDefinition(fields); err != nil {
+		return err
+	}
+	if err := p.run(s, st.body); err != nil {
+		p.defining = nil
+		return err
+	}
+	return p.endDefinition()
+}
+
+// expand обчислює вирази у полях команди
+func (s *script) expand(fields []field) ([]string, error) {
+	res := make([]string, len(fields))
+	for i, f := range fields {
+		res[i] = f.text
+		if f.quoted {
+			continue
+		}
+
+		// У параметрах на зразок rot=<expr> обчислюється лише значення
+		prefix, expr := "", f.text
+		if j := strings.IndexByte(expr, '='); j > 0 && isIdentifier(expr[:j]) {
+			prefix, expr = expr[:j+1], expr[j+1:]
+		}
+		if !strings.HasPrefix(expr, "(") && !strings.Contains(expr, "$") {
+			continue
+		}
+		v, err := evaluate(expr, s.vars)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = prefix + formatNumber(v)
+	}
+	return res, nil
+}
+
+// evaluate обчислює значення одного поля
+func (s *script) evaluate(f field) (float64, error) {
+	if f.quoted {
+		return 0, fmt.Errorf("expected number, got quoted string")
+	}
+	return evaluate(f.text, s.vars)
+}
+
+// formatNumber перетворює результат виразу на рядок. Значення округлюється до 9 знаків, щоб похибки
+// обчислень на зразок 0.1*3 не виводили координати за межі діапазону.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e9)/1e9, 'f', -1, 64)
+}
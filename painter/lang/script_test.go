@@ -0,0 +1,250 @@
+package lang
+
+import (
+	"errors"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+func TestEvaluate(t *testing.T) {
+	vars := map[string]float64{"i": 3, "step": 0.1}
+	for _, tc := range []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-$i + 10 % 4", -1},
+		{"$i * $step", 0.30000000000000004},
+		{"$i % 2 == 1 && !($i > 5)", 1},
+		{"$i <= 2 || $i != 3", 0},
+		{"max(1, min($i, 2)) + abs(-1)", 3},
+		{"floor(cos(180))", -1},
+	} {
+		got, err := evaluate(tc.expr, vars)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.expr, err)
+		} else if got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.expr, tc.want, got)
+		}
+	}
+
+	for _, tc := range []struct{ expr, err string }{
+		{"1 +", "unexpected end"},
+		{"$x", "undefined variable $x"},
+		{"1 / 0", "division by zero"},
+		{"foo(1)", "unknown function foo"},
+		{"max(1)", "expected 2 arguments"},
+		{"(1", "expected )"},
+		{"1 2", "unexpected \"2\""},
+		{"sqrt(-1)", "result is not a finite number"},
+	} {
+		if _, err := evaluate(tc.expr, vars); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected %q error, got %v", tc.expr, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseScript(t *testing.T) {
+	input := `let n = 3
+let step = 1 / ($n + 1)
+repeat $n i {
+  repeat 2 j {
+    if $j == 1 {
+      figure ($step * ($i + 1)) ($step * ($j + 1)) rot=$i*30 "#f00"
+    } else {
+      circle ($step * ($i + 1)) 0.1 0.05
+    }
+  }
+}
+text 0.1 0.9 0.05 "$n items"`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 7 {
+		t.Fatalf("Expected 7 operations, but got %d", len(ops))
+	}
+
+	for i, op := range ops[:3] {
+		circle := op.(painter.EllipseOperation)
+		if want := scale(0.25 * float64(i+1)); circle.X != want || circle.Y != scale(0.1) {
+			t.Errorf("Circle %d has incorrect position: %+v", i, circle)
+		}
+	}
+	if text := ops[3].(painter.TextOperation); text.Text != "$n items" {
+		t.Errorf("Expected quoted text to stay unchanged, got %q", text.Text)
+	}
+	for i, op := range ops[4:] {
+		fig := op.(*painter.FigureOperation)
+		if fig.X != scale(0.25*float64(i+1)) || fig.Y != scale(0.5) || fig.Rotation != float64(i*30) {
+			t.Errorf("Figure %d has incorrect parameters: %+v", i, fig)
+		}
+	}
+}
+
+func TestParser_ParseScriptDefine(t *testing.T) {
+	input := `define comb {
+  repeat 4 i {
+    rect (-0.2 + $i * 0.1) 0 (-0.15 + $i * 0.1) 0.1
+  }
+}
+figure comb 0.5 0.5`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if fig := ops[0].(*painter.FigureOperation); len(fig.Shape) != 4 {
+		t.Errorf("Expected figure with 4 parts, got %+v", fig)
+	}
+}
+
+func TestParser_ParseScriptErrors(t *testing.T) {
+	for _, tc := range []struct{ input, err string }{
+		{"repeat 2 {\nfigure 0.5 0.5", "unterminated repeat 2"},
+		{"}", "unexpected }"},
+		{"repeat 2 {\n} else {\n}", "unexpected else"},
+		{"repeat 1.5 {\n}", "must be a non-negative integer"},
+		{"repeat 1000 {\nrepeat 1000 {\n}\n}", "too many steps"},
+		{"let 1x = 2", "invalid let command format"},
+		{"figure $x 0.5", "undefined variable $x"},
+		{"figure (0.5 0.5", "unbalanced parentheses"},
+		{"move 1 0.5 {\n}", "unexpected block after move"},
+		{"define a {\ndefine b {\n}\n}", "nested define"},
+		{"let x = 2\nfigure $x 0.5", "X value 2.00 out of range"},
+		{"white\nlet x = sqrt(-1)", "line 2: invalid expression sqrt(-1): result is not a finite number"},
+		{"figure (sqrt(-1)) 0.5", "line 1: invalid expression (sqrt(-1)): result is not a finite number"},
+		{"circle NaN 0.5 0.1", "line 1: invalid argument 1 value: NaN is not a finite number"},
+		{"figure 0.5 0.5 rot=Inf", "line 1: invalid angle value: Inf is not a finite number"},
+	} {
+		parser := &Parser{}
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseSyntax(t *testing.T) {
+	input := `// Коментар на окремому рядку
+white; figure 0.5 0.5 #f00 # коментар після кольору
+repeat 2 i { circle x=(0.2 + $i * 0.1) y=0.3 r=0.05 }
+text x=0.1 y=0.9 size=0.05 text="a; b { c }" center
+if 1 { move 0.1 0.2 } else { move 0.9 0.9 }`
+
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(ops) != 6 {
+		t.Fatalf("Expected 6 operations, but got %d", len(ops))
+	}
+
+	if circle := ops[2].(painter.EllipseOperation); circle.X != scale(0.3) || circle.Y != scale(0.3) {
+		t.Errorf("Circle has incorrect position: %+v", circle)
+	}
+	if text := ops[3].(painter.TextOperation); text.Text != "a; b { c }" || text.Align != painter.AlignCenter {
+		t.Errorf("Text has incorrect parameters: %+v", text)
+	}
+	if move := ops[4].(*painter.MoveFiguresOperation); move.X != scale(0.1) {
+		t.Errorf("Expected then branch to run, got %+v", move)
+	}
+	if fig := ops[5].(*painter.FigureOperation); fig.Color != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("Expected # followed by hex digits to be a color, got %+v", fig)
+	}
+}
+
+func TestParser_ParseNamedArguments(t *testing.T) {
+	parser := &Parser{}
+	ops, err := parser.Parse(strings.NewReader("figure y=0.2 x=0.1 rot=30\nline 0.1 0.2 x2=0.3 y2=0.4"))
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if fig := ops[1].(*painter.FigureOperation); fig.X != scale(0.1) || fig.Y != scale(0.2) || fig.Rotation != 30 {
+		t.Errorf("Figure has incorrect parameters: %+v", fig)
+	}
+	if line := ops[0].(painter.LineOperation); line.X2 != scale(0.3) || line.Y2 != scale(0.4) || line.Width != 1 {
+		t.Errorf("Line has incorrect parameters: %+v", line)
+	}
+
+	for _, tc := range []struct{ input, err string }{
+		{"circle x=0.5 x=0.5 r=0.1", "duplicate argument x"},
+		{"circle x=0.5 r=0.1", "missing argument y"},
+		{"circle 0.5 0.5 x=0.5 r=0.1", "argument x is already given as positional"},
+	} {
+		if _, err := parser.Parse(strings.NewReader(tc.input)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: expected %q error, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestParser_ParseReportsAllErrors(t *testing.T) {
+	input := `figure 0.1 0.1
+circle 2 0.5 0.1
+text 0.1 0.1 0.1 "unterminated
+figure 0.2 0.2
+}
+bogus`
+
+	parser := &Parser{}
+	_, err := parser.Parse(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	for _, want := range []string{
+		"line 2: argument 1 value 2.00 out of range",
+		"line 3: unterminated quoted string",
+		"line 5: unexpected }",
+		"line 6: unknown command: bogus",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in errors, got:\n%v", want, err)
+		}
+	}
+
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Line == 0 {
+		t.Errorf("Expected ScriptError with line number, got %v", err)
+	}
+	// Скрипт з помилками не змінює сцену, навіть якщо частина команд коректна
+	if n := len(parser.Scene().Operations); n != 0 {
+		t.Errorf("Expected rejected script to leave the scene empty, got %d objects", n)
+	}
+}
+
+func TestParser_RejectedScriptKeepsState(t *testing.T) {
+	parser := &Parser{}
+	if _, err := parser.Parse(strings.NewReader("green\nfigure 0.1 0.1\nupdate")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := parser.Scene()
+
+	_, err := parser.Parse(strings.NewReader(`white
+figure 0.5 0.5
+define dot {
+  rect -0.1 0 0.1 0.2
+}
+macro twice() { figure 0.2 0.2 }
+bogus
+update`))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if !reflect.DeepEqual(parser.Scene(), before) {
+		t.Errorf("Expected rejected script not to change the scene")
+	}
+	if _, err := parser.Parse(strings.NewReader("figure dot 0.5 0.5\nupdate")); err == nil {
+		t.Errorf("Expected define from the rejected script to be discarded")
+	}
+	if _, err := parser.Parse(strings.NewReader("twice()\nupdate")); err == nil {
+		t.Errorf("Expected macro from the rejected script to be discarded")
+	}
+}
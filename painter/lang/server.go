@@ -0,0 +1,81 @@
+package lang
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Обмеження часу сервера за замовчуванням. ReadTimeout та WriteTimeout не поширюються на потоки подій та кадрів і на
+// з'єднання WebSocket, які самі знімають ці обмеження.
+const (
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+	DefaultMaxHeaderBytes    = 64 << 10
+)
+
+// Server HTTP сервер з обмеженнями часу та розміру заголовків. Якщо задані CertFile та KeyFile, сервер приймає
+// лише з'єднання TLS.
+type Server struct {
+	http.Server
+
+	CertFile string // Сертифікат у форматі PEM, разом з проміжними сертифікатами
+	KeyFile  string // Закритий ключ сертифіката у форматі PEM
+}
+
+// NewServer створює сервер для адреси addr з обмеженнями за замовчуванням.
+func NewServer(addr string, h http.Handler) *Server {
+	return &Server{Server: http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		ReadTimeout:       DefaultReadTimeout,
+		WriteTimeout:      DefaultWriteTimeout,
+		IdleTimeout:       DefaultIdleTimeout,
+		MaxHeaderBytes:    DefaultMaxHeaderBytes,
+	}}
+}
+
+// TLS повідомляє, чи сервер приймає з'єднання TLS.
+func (s *Server) TLS() bool {
+	return s.CertFile != "" || s.KeyFile != ""
+}
+
+// ListenAndServe слухає адресу Addr та обслуговує з'єднання, як Serve.
+func (s *Server) ListenAndServe() error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve обслуговує з'єднання з l, використовуючи TLS, якщо задані сертифікат та ключ.
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.check(); err != nil {
+		l.Close()
+		return err
+	}
+	if !s.TLS() {
+		return s.Server.Serve(l)
+	}
+	if s.TLSConfig == nil {
+		s.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return s.Server.ServeTLS(l, s.CertFile, s.KeyFile)
+}
+
+// check перевіряє, що сертифікат та ключ задані разом
+func (s *Server) check() error {
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return errors.New("both certificate and key files are required for TLS")
+	}
+	return nil
+}
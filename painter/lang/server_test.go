@@ -0,0 +1,140 @@
+package lang
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// selfSignedCert створює сертифікат для 127.0.0.1 та записує його разом з ключем у тимчасовий каталог
+func selfSignedCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "painter test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+// startServer запускає s на випадковому порту та повертає адресу сервера
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, pool := selfSignedCert(t)
+	var loop painter.Loop
+	s := NewServer("", &Handler{Loop: &loop, Parser: &Parser{MaxLines: 2}, MaxBodySize: 64})
+	s.CertFile, s.KeyFile = certFile, keyFile
+	addr := startServer(t, s)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	resp, err := client.Post("https://"+addr, "text/plain", strings.NewReader("white\nupdate"))
+	if err != nil {
+		t.Fatalf("TLS request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("Expected successful TLS response, got %d", resp.StatusCode)
+	}
+
+	for name, script := range map[string]string{
+		"body":  strings.Repeat("white\n", 20),
+		"lines": "white\nwhite\nupdate",
+	} {
+		resp, err := client.Post("https://"+addr, "text/plain", strings.NewReader(script))
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 for too many %s, got %d", name, resp.StatusCode)
+		}
+	}
+
+	// Запити без TLS сервер відхиляє
+	resp, err = http.Post("http://"+addr, "text/plain", strings.NewReader("update"))
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Errorf("Expected plain HTTP request to fail")
+		}
+	}
+}
+
+func TestServer_ReadHeaderTimeout(t *testing.T) {
+	s := NewServer("", http.NotFoundHandler())
+	s.ReadHeaderTimeout = 50 * time.Millisecond
+	addr := startServer(t, s)
+
+	// Клієнт, який не надсилає заголовки, не тримає з'єднання довше за обмеження
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\r\n"))
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Errorf("Expected server to close the connection, got %v", err)
+	}
+}
+
+func TestServer_CertWithoutKey(t *testing.T) {
+	s := NewServer("127.0.0.1:0", http.NotFoundHandler())
+	s.CertFile = "cert.pem"
+	if err := s.ListenAndServe(); err == nil || !strings.Contains(err.Error(), "both certificate and key") {
+		t.Errorf("Expected configuration error, got %v", err)
+	}
+}
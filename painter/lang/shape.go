@@ -0,0 +1,180 @@
+package lang
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// parseShape обробляє команди малювання простих фігур:
+//
+//	circle <x> <y> <r> [style]
+//	ellipse <x> <y> <rx> <ry> [style]
+//	line <x1> <y1> <x2> <y2> [width] [style]
+//	polygon <x1> <y1> <x2> <y2> <x3> <y3> ... [style]
+//
+// Параметри стилю (колір, непрозорість, режим змішування) описані у parseStyle.
+// Замість кольору фігура може бути заповнена градієнтом, описаним наприкінці команди (див. parseGradient):
+//
+//	circle 0.5 0.5 0.2 gradient radial #fff #000 0.5 0.5 0.2
+//
+// Усі координати та розміри задаються у нормалізованих одиницях від 0 до 1.
+func (p *Parser) parseShape(fields []string) (painter.Operation, error) {
+	command := fields[0]
+
+	var gradient *painter.Gradient
+	for i, f := range fields {
+		if f == "gradient" {
+			g, err := p.parseGradient(fields[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			gradient = &g
+			fields = fields[:i]
+			break
+		}
+	}
+
+	fields, st, err := parseStyle(fields)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseValues(fields[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case "circle":
+		if len(values) != 3 {
+			return nil, fmt.Errorf("invalid circle command format")
+		}
+		return painter.EllipseOperation{
+			X: p.scale(values[0]), Y: p.scale(values[1]), RX: p.scale(values[2]), RY: p.scale(values[2]),
+			Color: st.color, Gradient: gradient, Style: st.Style,
+		}, nil
+	case "ellipse":
+		if len(values) != 4 {
+			return nil, fmt.Errorf("invalid ellipse command format")
+		}
+		return painter.EllipseOperation{
+			X: p.scale(values[0]), Y: p.scale(values[1]), RX: p.scale(values[2]), RY: p.scale(values[3]),
+			Color: st.color, Gradient: gradient, Style: st.Style,
+		}, nil
+	case "line":
+		if len(values) != 4 && len(values) != 5 {
+			return nil, fmt.Errorf("invalid line command format")
+		}
+		width := 1.0 // Товщина лінії за замовчуванням - один піксель
+		if len(values) == 5 {
+			width = p.scale(values[4])
+		}
+		return painter.LineOperation{
+			X1: p.scale(values[0]), Y1: p.scale(values[1]), X2: p.scale(values[2]), Y2: p.scale(values[3]),
+			Width: width, Color: st.color, Gradient: gradient, Style: st.Style,
+		}, nil
+	case "polygon":
+		if len(values) < 6 || len(values)%2 != 0 {
+			return nil, fmt.Errorf("invalid polygon command format: expected at least 3 points")
+		}
+		points := make([]painter.Point, len(values)/2)
+		for i := range points {
+			points[i] = painter.Point{X: p.scale(values[2*i]), Y: p.scale(values[2*i+1])}
+		}
+		return painter.PolygonOperation{Points: points, Color: st.color, Gradient: gradient, Style: st.Style}, nil
+	}
+	return nil, fmt.Errorf("unknown command: %s", command)
+}
+
+// parseGradient розбирає опис градієнта:
+//
+//	linear <#from> <#to> <angle>
+//	radial <#from> <#to> <cx> <cy> <r>
+//
+// Кут лінійного градієнта задається у градусах (0 - зліва направо, 90 - згори донизу), центр та радіус
+// радіального - у нормалізованих одиницях відносно полотна.
+func (p *Parser) parseGradient(fields []string) (painter.Gradient, error) {
+	var g painter.Gradient
+	if len(fields) < 3 {
+		return g, fmt.Errorf("invalid gradient format")
+	}
+
+	var err error
+	if g.From, err = parseColor(fields[1]); err != nil {
+		return g, err
+	}
+	if g.To, err = parseColor(fields[2]); err != nil {
+		return g, err
+	}
+
+	switch fields[0] {
+	case "linear":
+		if len(fields) != 4 {
+			return g, fmt.Errorf("invalid linear gradient format")
+		}
+		if g.Angle, err = parseFloat(fields[3]); err != nil {
+			return g, fmt.Errorf("invalid gradient angle: %v", err)
+		}
+	case "radial":
+		if len(fields) != 6 {
+			return g, fmt.Errorf("invalid radial gradient format")
+		}
+		values, err := parseValues(fields[3:])
+		if err != nil {
+			return g, err
+		}
+		g.Radial = true
+		g.CX, g.CY, g.R = p.scale(values[0]), p.scale(values[1]), p.scale(values[2])
+	default:
+		return g, fmt.Errorf("unknown gradient type: %s", fields[0])
+	}
+	return g, nil
+}
+
+// errUnknownAsset помилка посилання на зображення, яке не було завантажене
+var errUnknownAsset = errors.New("unknown asset")
+
+// parseBlit обробляє команду малювання завантаженого зображення:
+//
+//	blit <name> <x> <y> [w h]
+//
+// Точка (x, y) задає лівий верхній кут, а w та h - розміри у нормалізованих одиницях. Без розмірів зображення
+// малюється у власному розмірі.
+func (p *Parser) parseBlit(fields []string) (painter.Operation, error) {
+	if len(fields) != 4 && len(fields) != 6 {
+		return nil, fmt.Errorf("invalid blit command format")
+	}
+
+	var img image.Image
+	if p.Assets != nil {
+		img, _ = p.Assets.Get(fields[1])
+	}
+	if img == nil {
+		return nil, fmt.Errorf("%w: %s", errUnknownAsset, fields[1])
+	}
+
+	values, err := parseValues(fields[2:])
+	if err != nil {
+		return nil, err
+	}
+	var w, h float64
+	if len(values) == 4 {
+		w, h = p.scale(values[2]), p.scale(values[3])
+	}
+	return painter.NewBlitOperation(img, p.scale(values[0]), p.scale(values[1]), w, h), nil
+}
+
+// parseValues перевіряє та перетворює список нормалізованих значень
+func parseValues(raw []string) ([]float64, error) {
+	values := make([]float64, len(raw))
+	for i, r := range raw {
+		v, err := parseCoordinates(r, fmt.Sprintf("argument %d", i+1))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,117 @@
+package lang
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+)
+
+// ServeStream виконує скрипти з потоку in, наприклад стандартного введення або іменованого каналу. Скрипт
+// закінчується нульовим байтом або кінцем потоку і виконується так само, як тіло запиту до HttpHandler, тому
+// генератор може надсилати багаторядкові скрипти з блоками. Відповіді на скрипти записуються в out, кожна окремим
+// рядком з номером скрипта в потоці, починаючи з 1:
+//
+//	ok <n>
+//	warning <n>: <message>
+//	error <n>: <message>
+//
+// Якщо out nil, помилки та попередження лише журналюються. Повертає nil, коли потік закінчився.
+func (h *Handler) ServeStream(remote string, in io.Reader, out io.Writer) error {
+	var w messageWriter = logMessages(remote)
+	if out != nil {
+		w = lineWriter{out}
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, int(h.maxBodySize())) // Скрипт обмежений так само, як тіло запиту
+	scanner.Split(splitScripts)
+	for n := 1; scanner.Scan(); n++ {
+		// Порожні скрипти, наприклад після завершального нульового байта, пропускаються
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := h.streamScript(w, remote, n, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return fmt.Errorf("script is longer than %d bytes", h.maxBodySize())
+	}
+	return scanner.Err()
+}
+
+// splitScripts розділяє потік на скрипти за нульовими байтами
+func splitScripts(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// streamScript виконує скрипт з потоку та надсилає клієнту результат
+func (h *Handler) streamScript(w messageWriter, remote string, n int, script string) error {
+	warnings, err := h.execute(remote, script, false)
+	if err != nil {
+		for _, e := range scriptErrors(err) {
+			if err := w.writeMessage(fmt.Sprintf("error %d: %v", n, e)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, warning := range warnings {
+		msg := *warning
+		msg.Warning = false // Відповідь вже позначена як попередження
+		if err := w.writeMessage(fmt.Sprintf("warning %d: %v", n, &msg)); err != nil {
+			return err
+		}
+	}
+	return w.writeMessage(fmt.Sprintf("ok %d", n))
+}
+
+// Serve приймає з'єднання з l, наприклад сокета Unix, та обслуговує кожне як ServeStream з відповідями у те саме
+// з'єднання. Повертає помилку, коли l закривається.
+func (h *Handler) Serve(l net.Listener) error {
+	remote := l.Addr().Network() + ":" + l.Addr().String()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := h.ServeStream(remote, conn, conn); err != nil {
+				log.Printf("Connection %s closed: %s", remote, err)
+			}
+		}()
+	}
+}
+
+// lineWriter записує кожну відповідь окремим рядком
+type lineWriter struct {
+	w io.Writer
+}
+
+func (lw lineWriter) writeMessage(msg string) error {
+	_, err := io.WriteString(lw.w, msg+"\n")
+	return err
+}
+
+// logMessages журналює помилки та попередження для потоків, у які не можна відповісти
+type logMessages string
+
+func (remote logMessages) writeMessage(msg string) error {
+	if !strings.HasPrefix(msg, "ok ") {
+		log.Printf("Script from %s: %s", string(remote), msg)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package lang
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+func TestHandler_ServeStream(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+		out    strings.Builder
+	)
+	h := &Handler{Loop: &loop, Parser: &parser}
+	// Скрипти розділяються нульовими байтами, а блоки в них можуть займати кілька рядків
+	in := "white\nupdate\x00\x00repeat 2 i {\n  figure ($i / 2) 0.5\n}\nupdate\x00circle 0.5 0.5 0.1\ncircle 2 0.5 0.1\nupdate"
+	if err := h.ServeStream("stdin", strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStream error: %v", err)
+	}
+
+	want := "ok 1\nok 3\nerror 4: line 2: argument 1 value 2.00 out of range [0.0 - 1.0]\n"
+	if out.String() != want {
+		t.Errorf("Unexpected replies:\n%s\nexpected:\n%s", out.String(), want)
+	}
+	if n := len(parser.Scene().Operations); n != 2 {
+		t.Errorf("Expected two figures on the scene, got %d objects", n)
+	}
+
+	out.Reset()
+	if err := h.ServeStream("stdin", strings.NewReader("figure 0.25 0.25"), &out); err != nil {
+		t.Fatalf("ServeStream error: %v", err)
+	}
+	if !strings.Contains(out.String(), "warning 1: script has no update command") || !strings.HasSuffix(out.String(), "ok 1\n") {
+		t.Errorf("Expected missing update warning, got %q", out.String())
+	}
+
+	h.MaxBodySize = 16
+	err := h.ServeStream("stdin", strings.NewReader(strings.Repeat("white; ", 10)), nil)
+	if err == nil || !strings.Contains(err.Error(), "script is longer than 16 bytes") {
+		t.Errorf("Expected script length error, got %v", err)
+	}
+}
+
+func TestHandler_Serve(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+	)
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "painter.sock"))
+	if err != nil {
+		t.Skipf("Unix sockets are not available: %v", err)
+	}
+	defer l.Close()
+	go (&Handler{Loop: &loop, Parser: &parser}).Serve(l)
+
+	// Кожне з'єднання нумерує скрипти окремо
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", l.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial error: %v", err)
+		}
+		r := bufio.NewReader(conn)
+		if _, err := conn.Write([]byte("green\nupdate\x00")); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+		if reply, err := r.ReadString('\n'); err != nil || reply != "ok 1\n" {
+			t.Errorf("Expected ok 1, got %q (%v)", reply, err)
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,89 @@
+package lang
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/dk872/architecture-lab3/painter"
+	"golang.org/x/exp/shiny/screen"
+)
+
+// shapeStyle колір та стиль змішування, задані у кінці команди
+type shapeStyle struct {
+	color color.Color
+	painter.Style
+}
+
+// parseStyle відокремлює параметри стилю в кінці команди та повертає решту полів:
+//
+//	#color        колір у форматі #rgb, #rgba, #rrggbb або #rrggbbaa
+//	opacity=<k>   непрозорість фігури від 0 до 1
+//	blend=<mode>  режим змішування: over (за замовчуванням) або src
+func parseStyle(fields []string) ([]string, shapeStyle, error) {
+	var st shapeStyle
+	for len(fields) > 1 {
+		ok, err := st.parseOption(fields[len(fields)-1])
+		if err != nil {
+			return nil, st, err
+		}
+		if !ok {
+			break
+		}
+		fields = fields[:len(fields)-1]
+	}
+	return fields, st, nil
+}
+
+// parseOption розбирає один параметр стилю; повертає false, якщо поле не є параметром стилю
+func (st *shapeStyle) parseOption(option string) (bool, error) {
+	switch {
+	case strings.HasPrefix(option, "#"):
+		c, err := parseColor(option)
+		if err != nil {
+			return false, err
+		}
+		st.color = c
+	case strings.HasPrefix(option, "opacity="):
+		k, err := parseFloat(strings.TrimPrefix(option, "opacity="))
+		if err != nil || k < 0 || k > 1 {
+			return false, fmt.Errorf("invalid opacity %s: expected value from 0 to 1", option)
+		}
+		st.Transparency = 1 - k
+	case option == "blend=over":
+		st.Blend = screen.Over
+	case option == "blend=src":
+		st.Blend = screen.Src
+	case strings.HasPrefix(option, "blend="):
+		return false, fmt.Errorf("unknown blend mode: %s", strings.TrimPrefix(option, "blend="))
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseColor розбирає колір у форматі #rgb, #rgba, #rrggbb або #rrggbbaa
+func parseColor(raw string) (color.Color, error) {
+	hex := strings.TrimPrefix(raw, "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		short := hex
+		hex = ""
+		for i := range short {
+			hex += short[i:i+1] + short[i:i+1]
+		}
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, fmt.Errorf("invalid color %s: expected #rgb, #rgba, #rrggbb or #rrggbbaa", raw)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %s: %v", raw, err)
+	}
+	c := color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+	return color.RGBAModel.Convert(c), nil
+}
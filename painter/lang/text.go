@@ -0,0 +1,59 @@
+package lang
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// maxTextLength максимальна довжина рядка тексту в символах
+const maxTextLength = 1000
+
+// parseText обробляє команду малювання тексту:
+//
+//	text <x> <y> <size> "<string>" [left|center|right] [style]
+//
+// Параметри стилю описані у parseStyle.
+// Координати та розмір шрифту задаються у нормалізованих одиницях, точка (x, y) лежить на базовій лінії тексту.
+func (p *Parser) parseText(fields []string) (painter.Operation, error) {
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("invalid text command format")
+	}
+	// Стиль шукається лише після тексту, щоб рядок на зразок "#1" не сприймався як колір.
+	rest, st, err := parseStyle(fields[4:])
+	if err != nil {
+		return nil, err
+	}
+	fields = append(fields[:4:4], rest...)
+	if len(fields) > 6 {
+		return nil, fmt.Errorf("invalid text command format")
+	}
+
+	if n := utf8.RuneCountInString(fields[4]); n > maxTextLength {
+		return nil, fmt.Errorf("text is too long: %d characters, limit is %d", n, maxTextLength)
+	}
+
+	values, err := parseValues(fields[1:4])
+	if err != nil {
+		return nil, err
+	}
+	op := painter.TextOperation{
+		X: p.scale(values[0]), Y: p.scale(values[1]), Size: p.scale(values[2]), Text: fields[4],
+		Color: st.color, Style: st.Style,
+	}
+
+	for _, option := range fields[5:] {
+		switch option {
+		case "left":
+			op.Align = painter.AlignLeft
+		case "center":
+			op.Align = painter.AlignCenter
+		case "right":
+			op.Align = painter.AlignRight
+		default:
+			return nil, fmt.Errorf("unknown text option: %s", option)
+		}
+	}
+	return op, nil
+}
@@ -0,0 +1,323 @@
+package lang
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocketHandler конструює обробник з'єднань WebSocket (RFC 6455), через які клієнт надсилає команди, не
+// відкриваючи нове HTTP з'єднання для кожного скрипта. Кожне текстове повідомлення розбивається на рядки, і кожен
+// рядок виконується як окремий скрипт так само, як запит до h. Блоки команд у такому рядку записуються через ;.
+// На кожен рядок сервер відповідає окремим повідомленням з його номером у з'єднанні, починаючи з 1:
+//
+//	ok <n>
+//	warning <n>: <message>
+//	error <n>[:<column>]: <message>
+//
+// Попередження надсилаються перед ok, а після помилок ok не надсилається.
+func WebSocketHandler(h *Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		conn, err := acceptWebSocket(rw, r)
+		if err != nil {
+			log.Printf("WebSocket handshake failed: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		line := 0
+		for {
+			msg, err := conn.readMessage()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					log.Printf("WebSocket connection closed: %s", err)
+				}
+				return
+			}
+			for _, script := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+				line++
+				if err := h.streamLine(conn, r.RemoteAddr, line, script); err != nil {
+					log.Printf("Failed to write to WebSocket: %s", err)
+					return
+				}
+			}
+		}
+	})
+}
+
+// messageWriter надсилає клієнту відповіді на рядки команд: повідомленнями WebSocket або рядками потоку
+type messageWriter interface {
+	writeMessage(msg string) error
+}
+
+// streamLine виконує рядок команд з потоку та надсилає клієнту результат
+func (h *Handler) streamLine(conn messageWriter, remote string, line int, script string) error {
+	warnings, err := h.execute(remote, script, true)
+	if err != nil {
+		for _, e := range scriptErrors(err) {
+			pos := fmt.Sprint(line)
+			if e.Column > 0 {
+				pos += fmt.Sprintf(":%d", e.Column)
+			}
+			if err := conn.writeMessage(fmt.Sprintf("error %s: %v", pos, e.Err)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, w := range warnings {
+		if err := conn.writeMessage(fmt.Sprintf("warning %d: %v", line, w.Err)); err != nil {
+			return err
+		}
+	}
+	return conn.writeMessage(fmt.Sprintf("ok %d", line))
+}
+
+// wsGUID рядок, який додається до ключа клієнта під час рукостискання
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWebSocketMessage максимальний розмір повідомлення WebSocket разом з усіма його фрагментами
+const maxWebSocketMessage = 1 << 20
+
+// Коди операцій кадрів WebSocket
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Коди закриття з'єднання WebSocket
+const (
+	closeNormal      = 1000
+	closeProtocol    = 1002
+	closeUnsupported = 1003
+	closeInvalidData = 1007
+	closeTooLarge    = 1009
+)
+
+// wsError помилка протоколу, після якої з'єднання закривається з кодом code
+type wsError struct {
+	code int
+	msg  string
+}
+
+func (e *wsError) Error() string {
+	return e.msg
+}
+
+// wsConn серверна сторона з'єднання WebSocket. Читання та запис виконуються з однієї горутини обробника.
+type wsConn struct {
+	rwc io.ReadWriteCloser
+	r   *bufio.Reader
+	w   *bufio.Writer
+}
+
+// acceptWebSocket перевіряє запит на оновлення протоколу та перехоплює з'єднання
+func acceptWebSocket(rw http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("unexpected method %s", r.Method)
+	case !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket"):
+		http.Error(rw, "expected WebSocket upgrade", http.StatusBadRequest)
+		return nil, fmt.Errorf("not a WebSocket upgrade request")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		rw.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(rw, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("unsupported version %q", r.Header.Get("Sec-WebSocket-Version"))
+	case key == "":
+		http.Error(rw, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("missing Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "WebSocket is not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	netConn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// З'єднання зберігає обмеження часу, які сервер встановив для запиту, а канал команд має жити довше за них
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(key + wsGUID))
+	fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(hash[:]))
+	if err := buf.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &wsConn{rwc: netConn, r: buf.Reader, w: buf.Writer}, nil
+}
+
+// headerContains перевіряє, чи заголовок містить токен у списку значень через кому
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readMessage повертає наступне текстове повідомлення, збираючи його з фрагментів. На ping сервер відповідає pong.
+// Коли клієнт закриває з'єднання, повертається io.EOF; після помилки протоколу клієнту надсилається кадр закриття.
+func (c *wsConn) readMessage() (string, error) {
+	var (
+		msg       []byte
+		fragments bool
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err == nil {
+			switch {
+			case opcode == opPing:
+				if err := c.writeFrame(opPong, payload); err != nil {
+					return "", err
+				}
+				continue
+			case opcode == opPong:
+				continue
+			case opcode == opClose:
+				c.close(closeNormal, "")
+				return "", io.EOF
+			case opcode == opBinary:
+				err = &wsError{closeUnsupported, "binary messages are not supported"}
+			case opcode == opContinuation && !fragments, opcode == opText && fragments:
+				err = &wsError{closeProtocol, "unexpected continuation frame"}
+			case opcode != opText && opcode != opContinuation:
+				err = &wsError{closeProtocol, fmt.Sprintf("unknown opcode %d", opcode)}
+			case len(msg)+len(payload) > maxWebSocketMessage:
+				err = &wsError{closeTooLarge, "message is too large"}
+			}
+		}
+
+		var protocolErr *wsError
+		if errors.As(err, &protocolErr) {
+			c.close(protocolErr.code, protocolErr.msg)
+			return "", err
+		}
+		if err != nil {
+			return "", err
+		}
+
+		msg = append(msg, payload...)
+		fragments = !fin
+		if fin {
+			if !utf8.Valid(msg) {
+				c.close(closeInvalidData, "invalid UTF-8")
+				return "", &wsError{closeInvalidData, "invalid UTF-8 in text message"}
+			}
+			return string(msg), nil
+		}
+	}
+}
+
+// readFrame зчитує один кадр. Кадри клієнта завжди мають маску.
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode = header[0]&0x80 != 0, header[0]&0x0f
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, &wsError{closeProtocol, "reserved bits are set"}
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, &wsError{closeProtocol, "client frame is not masked"}
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= opClose && (length > 125 || !fin) {
+		return false, 0, nil, &wsError{closeProtocol, "invalid control frame"}
+	}
+	if length > maxWebSocketMessage {
+		return false, 0, nil, &wsError{closeTooLarge, "message is too large"}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeMessage надсилає текстове повідомлення одним кадром.
+func (c *wsConn) writeMessage(msg string) error {
+	return c.writeFrame(opText, []byte(msg))
+}
+
+// writeFrame надсилає кадр без маски, як того вимагає протокол для сервера
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := c.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(payload); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// close надсилає кадр закриття з кодом та причиною
+func (c *wsConn) close(code int, reason string) {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	_ = c.writeFrame(opClose, append(payload, reason...))
+}
+
+// Close закриває мережеве з'єднання.
+func (c *wsConn) Close() error {
+	return c.rwc.Close()
+}
@@ -0,0 +1,167 @@
+package lang
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dk872/architecture-lab3/painter"
+)
+
+// wsClient мінімальний клієнт WebSocket для тестів
+type wsClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, url string) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Dial error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: painter\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatalf("Handshake error: %v", err)
+	}
+	// Значення з прикладу у RFC 6455
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected handshake response: %v %v", resp.Status, resp.Header)
+	}
+	return &wsClient{conn: conn, r: r}
+}
+
+// send надсилає кадр з маскою, як це робить клієнт
+func (c *wsClient) send(t *testing.T, fin bool, opcode byte, payload string) {
+	t.Helper()
+	header := []byte{opcode, 0x80 | byte(len(payload))}
+	if fin {
+		header[0] |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	data := append(header, mask...)
+	for i := range len(payload) {
+		data = append(data, payload[i]^mask[i%4])
+	}
+	if _, err := c.conn.Write(data); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+}
+
+// receive повертає код операції та вміст наступного кадру сервера
+func (c *wsClient) receive(t *testing.T) (byte, string) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if header[1]&0x80 != 0 {
+		t.Fatal("Server frame must not be masked")
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	return header[0] & 0x0f, string(payload)
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/ws", WebSocketHandler(&Handler{Loop: &loop, Parser: &parser}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := dialWebSocket(t, server.URL)
+	c.send(t, true, opText, "white\nfigure 0.5 0.5; update\ncircle 2 0.5 0.1; bogus")
+	c.send(t, true, opPing, "hi")
+	c.send(t, false, opText, "move 0.")
+	c.send(t, true, opContinuation, "2 0.2")
+
+	want := []struct {
+		opcode byte
+		text   string
+	}{
+		{opText, "ok 1"},
+		{opText, "ok 2"},
+		{opText, "error 3: argument 1 value 2.00 out of range"},
+		{opText, "error 3: unknown command: bogus"},
+		{opPong, "hi"},
+		{opText, "ok 4"},
+	}
+	for i, w := range want {
+		opcode, text := c.receive(t)
+		if opcode != w.opcode || !strings.HasPrefix(text, w.text) {
+			t.Errorf("Message %d: expected %d %q, got %d %q", i, w.opcode, w.text, opcode, text)
+		}
+	}
+
+	c.send(t, true, opClose, "")
+	if opcode, text := c.receive(t); opcode != opClose || binary.BigEndian.Uint16([]byte(text)) != closeNormal {
+		t.Errorf("Expected normal close frame, got %d %q", opcode, text)
+	}
+}
+
+func TestWebSocketHandler_Timeouts(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/ws", WebSocketHandler(&Handler{Loop: &loop, Parser: &parser}))
+	s := NewServer("", mux)
+	s.ReadTimeout, s.WriteTimeout = 50*time.Millisecond, 50*time.Millisecond
+	addr := startServer(t, s)
+
+	// Обмеження часу запиту не закривають з'єднання, яке вже перейшло на WebSocket
+	c := dialWebSocket(t, "http://"+addr)
+	time.Sleep(200 * time.Millisecond)
+	c.send(t, true, opText, "white; update")
+	if opcode, text := c.receive(t); opcode != opText || text != "ok 1" {
+		t.Errorf("Expected ok 1 after the server timeouts, got %d %q", opcode, text)
+	}
+}
+
+func TestWebSocketHandler_ProtocolErrors(t *testing.T) {
+	var (
+		loop   painter.Loop
+		parser Parser
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/ws", WebSocketHandler(&Handler{Loop: &loop, Parser: &parser}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/ws")
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for plain request, got %d", resp.StatusCode)
+	}
+
+	c := dialWebSocket(t, server.URL)
+	c.send(t, true, opBinary, "white")
+	if opcode, text := c.receive(t); opcode != opClose || binary.BigEndian.Uint16([]byte(text)) != closeUnsupported {
+		t.Errorf("Expected close for binary message, got %d %q", opcode, text)
+	}
+}
@@ -3,6 +3,7 @@ package painter
 import (
 	"image"
 	"sync"
+	"time"
 
 	"golang.org/x/exp/shiny/screen"
 )
@@ -14,25 +15,43 @@ type Receiver interface {
 
 // Loop реалізує цикл подій для формування текстури отриманої через виконання операцій отриманих з внутрішньої черги.
 type Loop struct {
-	Receiver Receiver
+	Receiver Receiver    // Отримує кадри синхронно у горутині циклу; інших отримувачів додає AddReceiver
+	Clock    Clock       // Джерело часу для відкладених операцій; якщо не задане, використовується реальний час
+	Size     image.Point // Розмір текстур; якщо не заданий, використовується 800×800
 
-	next screen.Texture // текстура, яка зараз формується
-	prev screen.Texture // текстура, яка була відправлення останнього разу у Receiver
+	screen screen.Screen // екран, для якого створено текстури
+
+	next *frame // кадр, який зараз формується
+	prev *frame // кадр, який був відправлений останнього разу у Receiver
 
 	mq messageQueue
 
 	stop    chan struct{}
 	stopReq bool
+
+	animations []*animation // анімації, які зараз виконуються
+
+	receivers receiverSet // отримувачі, додані через AddReceiver
 }
 
+// size розмір полотна за замовчуванням
 var size = image.Pt(800, 800)
 
-// Start запускає цикл подій. Цей метод потрібно запустити до того, як викликати на ньому будь-які інші методи.
+// Start запускає цикл подій. Операції, додані через Post та PostAfter до запуску, виконуються після нього.
 func (l *Loop) Start(s screen.Screen) {
-	l.next, _ = s.NewTexture(size)
-	l.prev, _ = s.NewTexture(size)
+	l.screen = s
+	if l.Size == (image.Point{}) {
+		l.Size = size
+	}
+	l.next = l.receivers.frame(s, l.Size)
+	l.prev = l.receivers.frame(s, l.Size)
 
-	l.mq = messageQueue{}
+	if l.stop == nil {
+		l.stop = make(chan struct{})
+	}
+
+	// Черга не створюється заново, щоб не втратити операції, додані до запуску циклу
+	l.mq.setClock(l.Clock)
 	go l.eventProcess()
 }
 
@@ -57,6 +76,10 @@ type messageQueue struct {
 	Queue   []Operation
 	mu      sync.Mutex
 	blocked chan struct{}
+
+	clock  Clock
+	timers timerHeap // відкладені операції
+	seq    uint64
 }
 
 func (mq *messageQueue) push(op Operation) {
@@ -82,11 +105,28 @@ func (mq *messageQueue) pushFront(op Operation) {
 func (mq *messageQueue) pull() Operation {
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
-	for mq.empty() {
-		mq.blocked = make(chan struct{})
+	for {
+		wait := mq.releaseTimers(mq.now())
+		if !mq.empty() {
+			break
+		}
+
+		blocked := make(chan struct{})
+		mq.blocked = blocked
 		mq.mu.Unlock()
-		<-mq.blocked
+		if wait < 0 {
+			<-blocked
+		} else {
+			// Чекаємо або нову операцію, або настання часу найближчого таймера.
+			select {
+			case <-blocked:
+			case <-mq.getClock().After(wait):
+			}
+		}
 		mq.mu.Lock()
+		if mq.blocked == blocked {
+			mq.blocked = nil
+		}
 	}
 
 	op := mq.Queue[0]
@@ -95,23 +135,87 @@ func (mq *messageQueue) pull() Operation {
 	return op
 }
 
+func (mq *messageQueue) setClock(c Clock) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.clock = c
+}
+
+func (mq *messageQueue) getClock() Clock {
+	if mq.clock == nil {
+		return realClock{}
+	}
+	return mq.clock
+}
+
+func (mq *messageQueue) now() time.Time {
+	return mq.getClock().Now()
+}
+
 func (mq *messageQueue) empty() bool {
 	return len(mq.Queue) == 0
 }
 
+// dropTimers видаляє відкладені операції, які не виконаються після зупинки циклу
+func (mq *messageQueue) dropTimers() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.timers = nil
+}
+
+// drained перевіряє, чи порожня черга, захищаючи доступ до неї від одночасного додавання операцій
+func (mq *messageQueue) drained() bool {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	return mq.empty()
+}
+
 func (l *Loop) eventProcess() {
 	for {
 
-		if l.stopReq && l.mq.empty() {
+		if l.stopReq && l.mq.drained() {
+			l.stopAnimations(func(*animation) bool { return true })
+			l.mq.dropTimers()
+			// Текстури кадрів звільняються, щоб зупинений цикл, наприклад видаленого полотна, не тримав пам'ять
+			l.receivers.release(l.next)
+			l.receivers.release(l.prev)
+			l.receivers.close()
 			close(l.stop)
 			return
 		}
 		if op := l.mq.pull(); op != nil {
-			if update := op.Do(l.next); update {
-				l.Receiver.Update(l.next)
-				l.next, l.prev = l.prev, l.next
+			if update := l.do(op); update {
+				if l.Receiver != nil {
+					l.Receiver.Update(l.next.texture)
+				}
+				l.receivers.update(l.next)
+				// Наступний кадр малюється на текстурі, яку не обробляє жоден отримувач
+				l.receivers.release(l.prev)
+				l.prev, l.next = l.next, l.receivers.frame(l.screen, l.Size)
 			}
 		}
 
 	}
 }
+
+// do виконує операцію над текстурою. Операції, які керують самим циклом (наприклад, анімації), обробляються тут же.
+func (l *Loop) do(op Operation) (ready bool) {
+	switch op := op.(type) {
+	case OperationList:
+		for _, o := range op {
+			ready = l.do(o) || ready
+		}
+		return
+	case *AnimateOperation:
+		l.animate(op)
+		return false
+	case stopAnimationsOp:
+		l.stopAnimations(func(*animation) bool { return true })
+		return false
+	case *MoveFiguresOperation:
+		l.cancelAnimations(*op.Figures)
+	case ScreenOperation:
+		return op.DoScreen(l.screen, l.next.texture)
+	}
+	return op.Do(l.next.texture)
+}
@@ -3,6 +3,7 @@ package painter
 import (
 	"image"
 	"image/color"
+	"math"
 
 	"golang.org/x/exp/shiny/screen"
 )
@@ -51,34 +52,111 @@ func GreenFill(t screen.Texture) {
 // RectOperation визначає координати прямокутника та малює його
 type RectOperation struct {
 	X1, Y1, X2, Y2 float64
+	Color          color.Color // Колір прямокутника, за замовчуванням чорний
+	Style
 }
 
 func (op RectOperation) Do(t screen.Texture) bool {
 	rect := image.Rect(int(op.X1), int(op.Y1), int(op.X2), int(op.Y2))
-	t.Fill(rect, color.Black, screen.Src)
+	op.fill(t, rect, shapeColor(op.Color))
 	return false
 }
 
 // FigureOperation визначає координати центру фігури та виконує малювання
 type FigureOperation struct {
-	X, Y float64
+	X, Y     float64
+	Shape    FigureShape // Частини фігури; nil означає стандартну T-подібну фігуру
+	Scale    float64     // Масштаб фігури; нульове значення означає початковий розмір
+	Rotation float64     // Кут повороту навколо центру у градусах за годинниковою стрілкою
+	Color    color.Color // Колір фігури, за замовчуванням жовтий
+	Style
 }
 
 func (op FigureOperation) Do(t screen.Texture) bool {
+	if op.Shape != nil || op.transformed() {
+		// Складені, повернуті або масштабовані фігури растеризуються як многокутники
+		for _, part := range op.parts(t.Size()) {
+			if len(part.Points) < 3 {
+				continue
+			}
+			box := boundingBox(part.Points)
+			fillSpans(t, float64(box.Min.Y), float64(box.Max.Y), polygonSpans(part.Points), part.Color, op.Style)
+		}
+		return false
+	}
+
+	// Заповнення прямокутників кольором фігури
+	for _, r := range op.rects(t.Size()) {
+		op.fill(t, r, op.color())
+	}
+	return false
+}
+
+// figureColor колір, яким малюється фігура за замовчуванням
+var figureColor = color.RGBA{R: 255, G: 255, B: 0, A: 255}
+
+func (op FigureOperation) color() color.Color {
+	if op.Color == nil {
+		return figureColor
+	}
+	return op.Color
+}
+
+// defaultScale повертає масштаб стандартної фігури: її розміри задані для полотна за замовчуванням, тому на
+// меншому полотні вона пропорційно менша
+func defaultScale(canvas image.Point) float64 {
+	return float64(canvas.X) / float64(size.X)
+}
+
+// rects повертає прямокутники, з яких складається стандартна фігура на полотні розміру canvas
+func (op FigureOperation) rects(canvas image.Point) []image.Rectangle {
 	// Розміри фігури
-	tWidth, tHeight := 400, 300
-	centerX, centerY := op.X, op.Y
+	k := defaultScale(canvas)
+	tWidth, tHeight := int(400*k), int(300*k)
+	centerX, centerY := int(op.X), int(op.Y)
 
 	// Горизонтальний прямокутник
-	horRect := image.Rect(int(centerX)-tWidth/2, int(centerY), int(centerX)+tWidth/2, int(centerY)-tHeight/2)
+	horRect := image.Rect(centerX-tWidth/2, centerY, centerX+tWidth/2, centerY-tHeight/2)
 	// Вертикальний прямокутник
-	verRect := image.Rect(int(centerX)-tWidth/5, int(centerY)+tHeight/2, int(centerX)+tWidth/5, int(centerY))
+	verRect := image.Rect(centerX-tWidth/5, centerY+tHeight/2, centerX+tWidth/5, centerY)
 
-	// Заповнення прямокутників жовтим кольором
-	t.Fill(horRect, color.RGBA{R: 255, G: 255, B: 0, A: 255}, screen.Src)
-	t.Fill(verRect, color.RGBA{R: 255, G: 255, B: 0, A: 255}, screen.Src)
+	return []image.Rectangle{horRect, verRect}
+}
 
-	return false
+// transformed перевіряє, чи фігуру повернуто або масштабовано
+func (op FigureOperation) transformed() bool {
+	return math.Mod(op.Rotation, 360) != 0 || (op.Scale != 0 && op.Scale != 1)
+}
+
+// parts повертає частини фігури у координатах текстури полотна розміру canvas після масштабування та повороту
+// навколо центру. Колір кожної частини вже визначено з урахуванням кольору фігури.
+func (op FigureOperation) parts(canvas image.Point) FigureShape {
+	scale := op.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	shape := op.Shape
+	if shape == nil {
+		shape = DefaultFigure
+		scale *= defaultScale(canvas)
+	}
+	sin, cos := math.Sincos(op.Rotation * math.Pi / 180)
+	center := Point{float64(int(op.X)), float64(int(op.Y))}
+
+	res := make(FigureShape, len(shape))
+	for i, part := range shape {
+		pts := make([]Point, len(part.Points))
+		for j, p := range part.Points {
+			dx, dy := p.X*scale, p.Y*scale
+			pts[j] = Point{center.X + dx*cos - dy*sin, center.Y + dx*sin + dy*cos}
+		}
+		c := part.Color
+		if c == nil {
+			c = op.color()
+		}
+		res[i] = FigurePart{Points: pts, Color: c}
+	}
+	return res
 }
 
 // MoveFiguresOperation переміщує всі фігури в нові координати
@@ -95,6 +173,32 @@ func (op MoveFiguresOperation) Do(t screen.Texture) bool {
 	return false
 }
 
+// RotateFiguresOperation встановлює кут повороту фігур
+type RotateFiguresOperation struct {
+	Degrees float64
+	Figures []*FigureOperation
+}
+
+func (op RotateFiguresOperation) Do(t screen.Texture) bool {
+	for _, f := range op.Figures {
+		f.Rotation = op.Degrees
+	}
+	return false
+}
+
+// ScaleFiguresOperation встановлює масштаб фігур
+type ScaleFiguresOperation struct {
+	Factor  float64
+	Figures []*FigureOperation
+}
+
+func (op ScaleFiguresOperation) Do(t screen.Texture) bool {
+	for _, f := range op.Figures {
+		f.Scale = op.Factor
+	}
+	return false
+}
+
 // ResetOperation очищає текстуру і зафарбовує її чорним
 func ResetOperation(t screen.Texture) {
 	t.Fill(t.Bounds(), color.Black, screen.Src)
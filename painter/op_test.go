@@ -0,0 +1,102 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestFigureOperation_Rotation(t *testing.T) {
+	tx := newImageTexture()
+	FigureOperation{X: 400, Y: 400, Rotation: 90}.Do(tx)
+
+	if !tx.isSet(475, 210) || !tx.isSet(330, 400) {
+		t.Error("Expected rotated rectangles to be filled")
+	}
+	if tx.isSet(390, 210) || tx.isSet(300, 260) {
+		t.Error("Expected original position of the horizontal rectangle to stay empty")
+	}
+}
+
+func TestFigureOperation_Scale(t *testing.T) {
+	tx := newImageTexture()
+	FigureOperation{X: 400, Y: 400, Scale: 0.5}.Do(tx)
+
+	if !tx.isSet(495, 330) || !tx.isSet(400, 470) {
+		t.Error("Expected scaled rectangles to be filled")
+	}
+	if tx.isSet(510, 330) || tx.isSet(400, 480) {
+		t.Error("Expected figure to be twice smaller")
+	}
+}
+
+func TestFigureOperation_FullTurnIsNotTransformed(t *testing.T) {
+	if (FigureOperation{Rotation: 360, Scale: 1}).transformed() {
+		t.Error("Expected full turn with unit scale to keep axis-aligned rendering")
+	}
+}
+
+func TestTransformOperations(t *testing.T) {
+	figures := []*FigureOperation{{}, {}}
+	RotateFiguresOperation{Degrees: 30, Figures: figures}.Do(nil)
+	ScaleFiguresOperation{Factor: 2, Figures: figures[1:]}.Do(nil)
+
+	if figures[0].Rotation != 30 || figures[1].Rotation != 30 {
+		t.Errorf("Expected all figures to be rotated, got %+v %+v", figures[0], figures[1])
+	}
+	if figures[0].Scale != 0 || figures[1].Scale != 2 {
+		t.Errorf("Expected only second figure to be scaled, got %+v %+v", figures[0], figures[1])
+	}
+}
+
+func TestFigureOperation_WriteSVGTransformed(t *testing.T) {
+	var out strings.Builder
+	if err := (FigureOperation{X: 400, Y: 400, Rotation: 90}).WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if n := strings.Count(out.String(), "<polygon"); n != 2 {
+		t.Errorf("Expected 2 polygons for rotated figure, got:\n%s", out.String())
+	}
+}
+
+func TestFigureOperation_Shape(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	tx := newImageTexture()
+	FigureOperation{X: 400, Y: 400, Shape: FigureShape{
+		RectPart(-50, -50, 50, 50, nil),
+		EllipsePart(100, 0, 20, 20, red),
+	}}.Do(tx)
+
+	if c := tx.img.RGBAAt(400, 400); c != figureColor {
+		t.Errorf("Expected part without color to use figure color, got %v", c)
+	}
+	if c := tx.img.RGBAAt(500, 400); c != red {
+		t.Errorf("Expected colored part to keep its color, got %v", c)
+	}
+	if tx.isSet(300, 300) || tx.isSet(525, 400) {
+		t.Error("Expected default T-shape not to be drawn")
+	}
+}
+
+func TestFigureOperation_ScalesWithCanvas(t *testing.T) {
+	tx := &imageTexture{img: image.NewRGBA(image.Rect(0, 0, 200, 200))}
+	FigureOperation{X: 100, Y: 100}.Do(tx)
+
+	// На полотні 200×200 стандартна фігура вчетверо менша: 100×75 пікселів замість 400×300
+	if c := tx.img.RGBAAt(100, 90); c != figureColor {
+		t.Errorf("Expected figure at the center, got %v", c)
+	}
+	if c := tx.img.RGBAAt(10, 90); c.A != 0 {
+		t.Errorf("Expected figure to fit the small canvas, got %v at (10, 90)", c)
+	}
+	if c := tx.img.RGBAAt(55, 90); c != figureColor {
+		t.Errorf("Expected scaled figure to be 100 pixels wide, got %v at (55, 90)", c)
+	}
+
+	rotated := &imageTexture{img: image.NewRGBA(image.Rect(0, 0, 200, 200))}
+	FigureOperation{X: 100, Y: 100, Rotation: 180}.Do(rotated)
+	if c := rotated.img.RGBAAt(10, 110); c.A != 0 {
+		t.Errorf("Expected rotated figure to be scaled as well, got %v at (10, 110)", c)
+	}
+}
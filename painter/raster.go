@@ -0,0 +1,83 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Point точка на текстурі у пікселях.
+type Point struct {
+	X, Y float64
+}
+
+// spanFunc повертає впорядковані пари координат X, між якими рядок пікселів з центром y належить фігурі
+type spanFunc func(y float64) []float64
+
+// fillSpans растеризує фігуру рядок за рядком у межах minY..maxY, заповнюючи кожен відрізок суцільним кольором
+// з урахуванням стилю.
+func fillSpans(t screen.Texture, minY, maxY float64, spans spanFunc, c color.Color, s Style) {
+	forEachSpan(t.Bounds(), minY, maxY, spans, func(r image.Rectangle) {
+		s.fill(t, r, c)
+	})
+}
+
+// forEachSpan викликає f для кожного відрізка рядка пікселів, який належить фігурі, в межах bounds.
+// Піксель вважається частиною фігури, якщо його центр потрапляє всередину.
+func forEachSpan(bounds image.Rectangle, minY, maxY float64, spans spanFunc, f func(r image.Rectangle)) {
+	for y := max(bounds.Min.Y, int(math.Floor(minY))); y < min(bounds.Max.Y, int(math.Ceil(maxY))); y++ {
+		xs := spans(float64(y) + 0.5)
+		for i := 0; i+1 < len(xs); i += 2 {
+			r := image.Rect(int(math.Ceil(xs[i]-0.5)), y, int(math.Ceil(xs[i+1]-0.5)), y+1).Intersect(bounds)
+			if !r.Empty() {
+				f(r)
+			}
+		}
+	}
+}
+
+// polygonSpans обчислює відрізки перетину рядка з многокутником за правилом парності
+func polygonSpans(pts []Point) spanFunc {
+	return func(y float64) []float64 {
+		var xs []float64
+		for i := range pts {
+			a, b := pts[i], pts[(i+1)%len(pts)]
+			if a.Y == b.Y {
+				continue
+			}
+			if (y >= a.Y && y < b.Y) || (y >= b.Y && y < a.Y) {
+				xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		sort.Float64s(xs)
+		return xs
+	}
+}
+
+// ellipseSpans обчислює відрізок перетину рядка з еліпсом
+func ellipseSpans(cx, cy, rx, ry float64) spanFunc {
+	return func(y float64) []float64 {
+		dy := (y - cy) / ry
+		if dy*dy >= 1 {
+			return nil
+		}
+		hw := rx * math.Sqrt(1-dy*dy)
+		return []float64{cx - hw, cx + hw}
+	}
+}
+
+// lineQuad повертає прямокутник заданої ширини, який покриває відрізок. Відрізок нульової довжини перетворюється
+// на квадрат зі стороною width.
+func lineQuad(x1, y1, x2, y2, width float64) []Point {
+	dx, dy := x2-x1, y2-y1
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		x1, x2 = x1-width/2, x2+width/2
+		dx, length = width, width
+	}
+	nx, ny := -dy/length*width/2, dx/length*width/2
+	return []Point{{x1 + nx, y1 + ny}, {x2 + nx, y2 + ny}, {x2 - nx, y2 - ny}, {x1 - nx, y1 - ny}}
+}
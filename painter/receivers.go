@@ -0,0 +1,209 @@
+package painter
+
+import (
+	"image"
+	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// AddReceiver додає отримувача кадрів, який працює незалежно від циклу та інших отримувачів: кожен отримує кадри у
+// власній горутині, а кадри, які він не встиг обробити, пропускаються, і отримувач одразу бачить останній. Поки
+// виконується Update, цикл не малює на текстурі кадру, а після повернення з Update використовує її повторно, тому
+// отримувач, якому потрібен вміст кадру пізніше, має скопіювати його в Update або реалізувати Display.
+// Отримувачів можна додавати та видаляти під час роботи циклу.
+func (l *Loop) AddReceiver(r Receiver) {
+	l.receivers.add(r)
+}
+
+// RemoveReceiver видаляє отримувача, доданого через AddReceiver, та чекає, поки він обробить кадр, який уже
+// отримав від циклу. Не можна викликати з Update того ж отримувача.
+func (l *Loop) RemoveReceiver(r Receiver) {
+	l.receivers.remove(r)
+}
+
+// Display отримувач, який використовує текстуру кадру і після повернення з Update, наприклад вікно, що
+// перемальовує її на кожну подію малювання. Цикл не малює на такій текстурі, доки отримувач не обробить наступний
+// кадр або не буде видалений.
+type Display interface {
+	Receiver
+	ShowsFrames()
+}
+
+// receiverSet розсилає кадри отримувачам, доданим через AddReceiver
+type receiverSet struct {
+	mu         sync.Mutex
+	deliveries map[Receiver]*delivery
+
+	framesMu sync.Mutex
+	free     []*frame // Кадри, текстури яких не використовують ні цикл, ні отримувачі
+	closed   bool     // Цикл зупинено: текстури кадрів без власників звільняються
+}
+
+// frame текстура кадру з кількістю її власників: циклу та отримувачів, які ще не обробили кадр
+type frame struct {
+	texture screen.Texture
+	refs    int
+}
+
+// frame повертає кадр, який ніхто не використовує, або створює новий. Власником кадру стає цикл.
+func (rs *receiverSet) frame(s screen.Screen, size image.Point) *frame {
+	rs.framesMu.Lock()
+	defer rs.framesMu.Unlock()
+
+	var f *frame
+	if n := len(rs.free); n > 0 {
+		f, rs.free = rs.free[n-1], rs.free[:n-1]
+	} else {
+		f = &frame{}
+		f.texture, _ = s.NewTexture(size)
+	}
+	f.refs = 1
+	rs.closed = false // Цикл запущено знову
+	return f
+}
+
+func (rs *receiverSet) hold(f *frame) {
+	rs.framesMu.Lock()
+	defer rs.framesMu.Unlock()
+	f.refs++
+}
+
+// release звільняє кадр від одного власника; кадр без власників знову використовується циклом
+func (rs *receiverSet) release(f *frame) {
+	rs.framesMu.Lock()
+	defer rs.framesMu.Unlock()
+	if f.refs--; f.refs != 0 {
+		return
+	}
+	if rs.closed {
+		f.release()
+	} else {
+		rs.free = append(rs.free, f)
+	}
+}
+
+// close звільняє текстури кадрів після зупинки циклу. Кадри, які ще обробляють отримувачі, звільняються, коли
+// отримувачі їх повернуть.
+func (rs *receiverSet) close() {
+	rs.framesMu.Lock()
+	defer rs.framesMu.Unlock()
+	rs.closed = true
+	for _, f := range rs.free {
+		f.release()
+	}
+	rs.free = nil
+}
+
+func (f *frame) release() {
+	if f.texture != nil {
+		f.texture.Release()
+	}
+}
+
+func (rs *receiverSet) add(r Receiver) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if _, ok := rs.deliveries[r]; ok {
+		return
+	}
+	if rs.deliveries == nil {
+		rs.deliveries = make(map[Receiver]*delivery)
+	}
+	d := &delivery{receiver: r, set: rs, wake: make(chan struct{}, 1), done: make(chan struct{}), stopped: make(chan struct{})}
+	_, d.display = r.(Display)
+	rs.deliveries[r] = d
+	go d.run()
+}
+
+func (rs *receiverSet) remove(r Receiver) {
+	rs.mu.Lock()
+	d, ok := rs.deliveries[r]
+	delete(rs.deliveries, r)
+	rs.mu.Unlock()
+
+	if ok {
+		close(d.done)
+		<-d.stopped
+	}
+}
+
+// update передає кадр усім отримувачам, не чекаючи на них
+func (rs *receiverSet) update(f *frame) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, d := range rs.deliveries {
+		rs.hold(f)
+		d.post(f)
+	}
+}
+
+// delivery черга на один кадр для окремого отримувача: новий кадр замінює той, що ще не був переданий
+type delivery struct {
+	receiver Receiver
+	set      *receiverSet
+
+	mu      sync.Mutex
+	pending *frame
+
+	display bool   // Отримувач реалізує Display
+	shown   *frame // Кадр, який Display показує до обробки наступного
+
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func (d *delivery) post(f *frame) {
+	d.mu.Lock()
+	skipped := d.pending
+	d.pending = f
+	d.mu.Unlock()
+	if skipped != nil {
+		d.set.release(skipped)
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default: // Отримувач ще не забрав попередній кадр
+	}
+}
+
+func (d *delivery) run() {
+	defer close(d.stopped)
+	for {
+		select {
+		case <-d.wake:
+		case <-d.done:
+			// Кадр, який надійшов до видалення отримувача, ще передається йому, щоб, наприклад, запис не втратив
+			// останній кадр
+			d.deliver()
+			if d.shown != nil {
+				d.set.release(d.shown)
+			}
+			return
+		}
+		d.deliver()
+	}
+}
+
+// deliver передає отримувачу кадр, що очікує, та повертає його циклу
+func (d *delivery) deliver() {
+	d.mu.Lock()
+	f := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	if f == nil {
+		return
+	}
+	d.receiver.Update(f.texture)
+	if d.display {
+		// Попередній кадр більше не показується, а поточний залишається у Display до наступного
+		f, d.shown = d.shown, f
+	}
+	if f != nil {
+		d.set.release(f)
+	}
+}
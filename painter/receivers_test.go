@@ -0,0 +1,215 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// channelReceiver передає кожен отриманий кадр у канал
+type channelReceiver struct {
+	frames chan screen.Texture
+}
+
+func (r *channelReceiver) Update(t screen.Texture) {
+	r.frames <- t
+}
+
+// blockedReceiver обробляє кадр, лише коли тест дозволить
+type blockedReceiver struct {
+	mu      sync.Mutex
+	release chan struct{}
+	updates int
+}
+
+func (r *blockedReceiver) Update(screen.Texture) {
+	<-r.release
+	r.mu.Lock()
+	r.updates++
+	r.mu.Unlock()
+}
+
+func (r *blockedReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updates
+}
+
+// fillCounter рахує заповнення текстур, щоб перевірити, що цикл не малює на кадрі, який обробляє отримувач
+type fillCounter struct{}
+
+func (fillCounter) NewBuffer(image.Point) (screen.Buffer, error)              { return nil, nil }
+func (fillCounter) NewWindow(*screen.NewWindowOptions) (screen.Window, error) { return nil, nil }
+func (fillCounter) NewTexture(image.Point) (screen.Texture, error) {
+	return new(countingTexture), nil
+}
+
+type countingTexture struct {
+	mockTexture
+	fills atomic.Int32
+}
+
+func (t *countingTexture) Fill(image.Rectangle, color.Color, draw.Op) { t.fills.Add(1) }
+
+// laggingReceiver обробляє кожен кадр довше, ніж цикл малює наступні
+type laggingReceiver struct {
+	redrawn atomic.Bool
+	updates atomic.Int32
+}
+
+func (r *laggingReceiver) Update(t screen.Texture) {
+	before := t.(*countingTexture).fills.Load()
+	time.Sleep(5 * time.Millisecond)
+	if t.(*countingTexture).fills.Load() != before {
+		r.redrawn.Store(true)
+	}
+	r.updates.Add(1)
+}
+
+func TestLoop_AddReceiver_TextureNotReused(t *testing.T) {
+	var l Loop
+	lagging := &laggingReceiver{}
+	l.AddReceiver(lagging)
+	l.Start(fillCounter{})
+
+	for range 50 {
+		l.Post(OperationList{OperationFunc(WhiteFill), UpdateOp})
+		time.Sleep(time.Millisecond)
+	}
+	l.StopAndWait()
+	l.RemoveReceiver(lagging)
+
+	if lagging.updates.Load() == 0 {
+		t.Fatal("Receiver got no frames")
+	}
+	if lagging.redrawn.Load() {
+		t.Error("Loop drew on a texture while the receiver was processing it")
+	}
+}
+
+// shownReceiver показує кожен кадр до наступного та перевіряє, що цикл тим часом на ньому не малював
+type shownReceiver struct {
+	shown   *countingTexture
+	fills   int32
+	redrawn atomic.Bool
+	updates atomic.Int32
+}
+
+func (r *shownReceiver) ShowsFrames() {}
+
+func (r *shownReceiver) Update(t screen.Texture) {
+	time.Sleep(2 * time.Millisecond) // Вікно показує попередній кадр, поки новий ще не оброблено
+	if r.shown != nil && r.shown.fills.Load() != r.fills {
+		r.redrawn.Store(true)
+	}
+	r.shown = t.(*countingTexture)
+	r.fills = r.shown.fills.Load()
+	r.updates.Add(1)
+}
+
+func TestLoop_AddReceiver_DisplayKeepsFrame(t *testing.T) {
+	var l Loop
+	display := &shownReceiver{}
+	l.AddReceiver(display)
+	l.Start(fillCounter{})
+
+	for range 50 {
+		l.Post(OperationList{OperationFunc(WhiteFill), UpdateOp})
+		time.Sleep(time.Millisecond)
+	}
+	l.StopAndWait()
+	l.RemoveReceiver(display)
+
+	if display.updates.Load() < 2 {
+		t.Fatalf("Display got %d frames", display.updates.Load())
+	}
+	if display.redrawn.Load() {
+		t.Error("Loop drew on the texture the display was showing")
+	}
+}
+
+func TestLoop_AddReceiver(t *testing.T) {
+	var l Loop
+	fast := &channelReceiver{frames: make(chan screen.Texture, 10)}
+	slow := &blockedReceiver{release: make(chan struct{})}
+	l.AddReceiver(fast)
+	l.AddReceiver(slow)
+	l.Start(mockScreen{})
+
+	// Повільний отримувач не затримує цикл та швидкого отримувача
+	for range 3 {
+		l.Post(UpdateOp)
+		select {
+		case <-fast.frames:
+		case <-time.After(time.Second):
+			t.Fatal("Fast receiver did not get the frame")
+		}
+	}
+
+	// Кадри, які надійшли, поки повільний отримувач був зайнятий, пропускаються
+	close(slow.release)
+	l.RemoveReceiver(fast)
+	l.Post(UpdateOp)
+	l.StopAndWait()
+	for deadline := time.Now().Add(time.Second); slow.count() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	l.RemoveReceiver(slow)
+
+	if slow.updates == 0 || slow.updates > 3 {
+		t.Errorf("Expected skipped frames for slow receiver, got %d updates", slow.updates)
+	}
+	select {
+	case <-fast.frames:
+		t.Error("Removed receiver got a frame")
+	default:
+	}
+}
+
+// releaseCounter рахує створені та звільнені текстури
+type releaseCounter struct {
+	created, released *atomic.Int32
+}
+
+func (releaseCounter) NewBuffer(image.Point) (screen.Buffer, error)              { return nil, nil }
+func (releaseCounter) NewWindow(*screen.NewWindowOptions) (screen.Window, error) { return nil, nil }
+func (s releaseCounter) NewTexture(image.Point) (screen.Texture, error) {
+	s.created.Add(1)
+	return &releasedTexture{released: s.released}, nil
+}
+
+type releasedTexture struct {
+	mockTexture
+	released *atomic.Int32
+}
+
+func (t *releasedTexture) Release() { t.released.Add(1) }
+
+func TestLoop_StopReleasesTextures(t *testing.T) {
+	s := releaseCounter{created: new(atomic.Int32), released: new(atomic.Int32)}
+	var l Loop
+	slow := &blockedReceiver{release: make(chan struct{})}
+	l.AddReceiver(slow)
+	l.Start(s)
+
+	for range 5 {
+		l.Post(OperationList{OperationFunc(WhiteFill), UpdateOp})
+	}
+	l.StopAndWait()
+	if s.created.Load() == s.released.Load() {
+		t.Error("Expected the frame held by the receiver to stay until it is processed")
+	}
+
+	// Кадр, який обробляв отримувач, звільняється після обробки
+	close(slow.release)
+	l.RemoveReceiver(slow)
+	if created, released := s.created.Load(), s.released.Load(); created != released {
+		t.Errorf("Expected all %d textures to be released, got %d", created, released)
+	}
+}
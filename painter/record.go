@@ -0,0 +1,197 @@
+package painter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// DefaultMaxFrames кількість кадрів, після якої Recorder перестає записувати GIF, щоб не вичерпати пам'ять
+const DefaultMaxFrames = 1000
+
+// ErrUnreadableTexture повертається, якщо записувач отримував кадри, але вміст жодного з них не можна прочитати.
+var ErrUnreadableTexture = errors.New("frames cannot be read from this screen: use the headless screen")
+
+// ErrNoFrames повертається, якщо записувач не отримав жодного кадру.
+var ErrNoFrames = errors.New("no frames were received")
+
+// snapshotter реалізується текстурами, вміст яких можна скопіювати, наприклад текстурами екрана без вікна
+type snapshotter interface {
+	Snapshot() *image.RGBA
+}
+
+// Recorder записує кадри, які отримує як Receiver, у анімований GIF або у послідовність PNG файлів. Однакові кадри
+// поспіль об'єднуються в один з більшою тривалістю. Читати можна лише текстури, які підтримують Snapshot.
+type Recorder struct {
+	Clock     Clock // Джерело часу для тривалості кадрів; якщо не задане, використовується реальний час
+	MaxFrames int   // Максимальна кількість кадрів GIF; 0 означає DefaultMaxFrames
+
+	mu       sync.Mutex
+	gif      io.Writer // Куди записати GIF під час Close; nil для послідовності PNG
+	dir      string    // Каталог для послідовності PNG
+	frames   []*image.Paletted
+	times    []time.Time // Час отримання кожного кадру GIF
+	prev     []byte      // Пікселі попереднього кадру, щоб пропускати однакові кадри
+	count    int
+	dropped  int
+	received bool // Отримано хоча б один кадр
+	readable bool // Хоча б один отриманий кадр вдалося прочитати
+	err      error
+}
+
+// NewGIFRecorder створює записувач, який під час Close записує анімований GIF у w.
+func NewGIFRecorder(w io.Writer) *Recorder {
+	return &Recorder{gif: w}
+}
+
+// NewPNGRecorder створює записувач, який одразу зберігає кожен кадр у каталог dir як frame-00001.png,
+// frame-00002.png і так далі.
+func NewPNGRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir}
+}
+
+func (r *Recorder) Update(t screen.Texture) {
+	s, ok := t.(snapshotter)
+	if !ok {
+		r.mu.Lock()
+		r.received = true
+		r.mu.Unlock()
+		return
+	}
+	img := s.Snapshot()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.received, r.readable = true, true
+	if r.err != nil || bytes.Equal(img.Pix, r.prev) {
+		return
+	}
+	r.prev = img.Pix
+
+	if r.gif == nil {
+		r.count++
+		r.err = writePNG(filepath.Join(r.dir, fmt.Sprintf("frame-%05d.png", r.count)), img)
+		return
+	}
+
+	maxFrames := r.MaxFrames
+	if maxFrames == 0 {
+		maxFrames = DefaultMaxFrames
+	}
+	if len(r.frames) >= maxFrames {
+		r.dropped++
+		return
+	}
+	r.count++
+	r.frames = append(r.frames, quantize(img))
+	r.times = append(r.times, r.now())
+}
+
+// Close завершує запис: записує GIF або повертає першу помилку запису PNG. Повертає ErrNoFrames, якщо кадрів не
+// було, та ErrUnreadableTexture, якщо жоден отриманий кадр не вдалося прочитати.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if !r.received {
+		return ErrNoFrames
+	}
+	if !r.readable {
+		return ErrUnreadableTexture
+	}
+	if r.gif == nil || len(r.frames) == 0 {
+		return nil
+	}
+
+	// Тривалість кадру - час до наступного кадру в сотих секунди; останній кадр триває до завершення запису
+	delays := make([]int, len(r.frames))
+	for i := range r.frames {
+		end := r.now()
+		if i+1 < len(r.times) {
+			end = r.times[i+1]
+		}
+		delays[i] = max(int(end.Sub(r.times[i])/(10*time.Millisecond)), 2) // Браузери не показують коротші кадри
+	}
+	return gif.EncodeAll(r.gif, &gif.GIF{Image: r.frames, Delay: delays})
+}
+
+// Frames повертає кількість записаних кадрів.
+func (r *Recorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Dropped повертає кількість кадрів, пропущених після досягнення MaxFrames.
+func (r *Recorder) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+func (r *Recorder) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// quantize перетворює кадр у зображення з палітрою. Кадри з не більше ніж 256 кольорами, як більшість сцен з
+// однотонних фігур, зберігають точні кольори; для решти використовується палітра Plan 9 з розсіюванням похибки.
+func quantize(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+	index := make(map[color.RGBA]uint8)
+	var pal color.Palette
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if _, ok := index[c]; ok {
+				continue
+			}
+			if len(pal) == 256 {
+				res := image.NewPaletted(b, palette.Plan9)
+				draw.FloydSteinberg.Draw(res, b, img, b.Min)
+				return res
+			}
+			index[c] = uint8(len(pal))
+			pal = append(pal, c)
+		}
+	}
+
+	res := image.NewPaletted(b, pal)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			res.SetColorIndex(x, y, index[img.RGBAAt(x, y)])
+		}
+	}
+	return res
+}
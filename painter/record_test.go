@@ -0,0 +1,126 @@
+package painter
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// snapshotTexture текстура, вміст якої можна прочитати, як у екрана без вікна
+type snapshotTexture struct {
+	mockTexture
+	img *image.RGBA
+}
+
+func (t *snapshotTexture) Snapshot() *image.RGBA {
+	res := image.NewRGBA(t.img.Rect)
+	copy(res.Pix, t.img.Pix)
+	return res
+}
+
+func solidTexture(c color.Color) *snapshotTexture {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range 16 {
+		img.Set(i%4, i/4, c)
+	}
+	return &snapshotTexture{img: img}
+}
+
+func TestRecorder_GIF(t *testing.T) {
+	clock := newFakeClock()
+	var out bytes.Buffer
+	r := NewGIFRecorder(&out)
+	r.Clock = clock
+
+	red, blue := solidTexture(color.RGBA{R: 255, A: 255}), solidTexture(color.RGBA{B: 255, A: 255})
+	r.Update(red)
+	clock.Advance(100 * time.Millisecond)
+	r.Update(red) // Однаковий кадр подовжує попередній
+	clock.Advance(200 * time.Millisecond)
+	r.Update(blue)
+	clock.Advance(5 * time.Millisecond)
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if r.Frames() != 2 {
+		t.Errorf("Expected 2 frames, got %d", r.Frames())
+	}
+
+	g, err := gif.DecodeAll(&out)
+	if err != nil {
+		t.Fatalf("Invalid GIF: %v", err)
+	}
+	if len(g.Image) != 2 || g.Delay[0] != 30 || g.Delay[1] != 2 {
+		t.Errorf("Unexpected frames %d with delays %v", len(g.Image), g.Delay)
+	}
+	if c := color.RGBAModel.Convert(g.Image[1].At(1, 1)); c != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("Expected exact blue color, got %v", c)
+	}
+}
+
+func TestRecorder_GIFFrameLimit(t *testing.T) {
+	var out bytes.Buffer
+	r := NewGIFRecorder(&out)
+	r.MaxFrames = 1
+	r.Update(solidTexture(color.White))
+	r.Update(solidTexture(color.Black))
+
+	if err := r.Close(); err != nil || r.Frames() != 1 || r.Dropped() != 1 {
+		t.Errorf("Expected one frame and one dropped, got %d/%d: %v", r.Frames(), r.Dropped(), err)
+	}
+}
+
+func TestRecorder_PNG(t *testing.T) {
+	dir := t.TempDir()
+	r := NewPNGRecorder(dir)
+	r.Update(solidTexture(color.White))
+	r.Update(solidTexture(color.Black))
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "frame-00002.png"))
+	if err != nil {
+		t.Fatalf("Expected second frame file: %v", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if c := color.GrayModel.Convert(img.At(0, 0)); c != (color.Gray{}) {
+		t.Errorf("Expected black frame, got %v", c)
+	}
+}
+
+func TestRecorder_UnreadableTexture(t *testing.T) {
+	r := NewGIFRecorder(&bytes.Buffer{})
+	r.Update(new(mockTexture))
+	if err := r.Close(); !errors.Is(err, ErrUnreadableTexture) {
+		t.Errorf("Expected unreadable texture error, got %v", err)
+	}
+}
+
+func TestRecorder_NoFrames(t *testing.T) {
+	if err := NewGIFRecorder(&bytes.Buffer{}).Close(); !errors.Is(err, ErrNoFrames) {
+		t.Errorf("Expected no frames error, got %v", err)
+	}
+}
+
+func TestQuantize_ManyColors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for i := range 1024 {
+		img.Set(i%32, i/32, color.RGBA{R: uint8(i), G: uint8(i / 4), A: 255})
+	}
+	if p := quantize(img); len(p.Palette) > 256 || p.Bounds() != img.Bounds() {
+		t.Errorf("Unexpected quantized image with %d colors", len(p.Palette))
+	}
+}
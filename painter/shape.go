@@ -0,0 +1,145 @@
+package painter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// shapeColor повертає колір фігури, за замовчуванням чорний
+func shapeColor(c color.Color) color.Color {
+	if c == nil {
+		return color.Black
+	}
+	return c
+}
+
+// shapeFill описує заливку простої фігури: суцільний колір або градієнт, якщо він заданий, та стиль змішування
+type shapeFill struct {
+	Color    color.Color
+	Gradient *Gradient
+	Style
+}
+
+// fill растеризує фігуру з обмежувальним прямокутником box
+func (f shapeFill) fill(s screen.Screen, t screen.Texture, box image.Rectangle, spans spanFunc) {
+	if f.Gradient != nil {
+		fillGradient(s, t, box, spans, *f.Gradient, f.Style)
+	} else {
+		fillSpans(t, float64(box.Min.Y), float64(box.Max.Y), spans, shapeColor(f.Color), f.Style)
+	}
+}
+
+// svg записує визначення градієнта за потреби та повертає атрибути заливки
+func (f shapeFill) svg(w io.Writer, box image.Rectangle) (string, error) {
+	if f.Gradient != nil {
+		fill, err := writeSVGGradient(w, *f.Gradient, box)
+		return fill + f.Style.svg(), err
+	}
+	return svgFill(shapeColor(f.Color)) + f.Style.svg(), nil
+}
+
+// EllipseOperation малює еліпс із центром (X, Y) та радіусами RX, RY. Коло є еліпсом з однаковими радіусами.
+type EllipseOperation struct {
+	X, Y, RX, RY float64
+	Color        color.Color
+	Gradient     *Gradient // Якщо заданий, використовується замість Color
+	Style
+}
+
+func (op EllipseOperation) Do(t screen.Texture) bool {
+	return op.DoScreen(nil, t)
+}
+
+func (op EllipseOperation) DoScreen(s screen.Screen, t screen.Texture) bool {
+	if op.RX > 0 && op.RY > 0 {
+		shapeFill{op.Color, op.Gradient, op.Style}.fill(s, t, op.box(), ellipseSpans(op.X, op.Y, op.RX, op.RY))
+	}
+	return false
+}
+
+func (op EllipseOperation) box() image.Rectangle {
+	return boundingBox([]Point{{op.X - op.RX, op.Y - op.RY}, {op.X + op.RX, op.Y + op.RY}})
+}
+
+func (op EllipseOperation) WriteSVG(w io.Writer) error {
+	fill, err := shapeFill{op.Color, op.Gradient, op.Style}.svg(w, op.box())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, `  <ellipse cx="%g" cy="%g" rx="%g" ry="%g" %s/>`+"\n", op.X, op.Y, op.RX, op.RY, fill)
+	return err
+}
+
+// LineOperation малює відрізок від (X1, Y1) до (X2, Y2) заданої ширини.
+type LineOperation struct {
+	X1, Y1, X2, Y2 float64
+	Width          float64
+	Color          color.Color
+	Gradient       *Gradient // Якщо заданий, використовується замість Color
+	Style
+}
+
+func (op LineOperation) Do(t screen.Texture) bool {
+	return op.DoScreen(nil, t)
+}
+
+func (op LineOperation) DoScreen(s screen.Screen, t screen.Texture) bool {
+	if op.Width > 0 {
+		fillPolygon(s, t, lineQuad(op.X1, op.Y1, op.X2, op.Y2, op.Width), shapeFill{op.Color, op.Gradient, op.Style})
+	}
+	return false
+}
+
+func (op LineOperation) WriteSVG(w io.Writer) error {
+	return writeSVGPolygon(w, lineQuad(op.X1, op.Y1, op.X2, op.Y2, op.Width), shapeFill{op.Color, op.Gradient, op.Style})
+}
+
+// PolygonOperation малює довільний многокутник за вершинами. Самоперетини заповнюються за правилом парності.
+type PolygonOperation struct {
+	Points   []Point
+	Color    color.Color
+	Gradient *Gradient // Якщо заданий, використовується замість Color
+	Style
+}
+
+func (op PolygonOperation) Do(t screen.Texture) bool {
+	return op.DoScreen(nil, t)
+}
+
+func (op PolygonOperation) DoScreen(s screen.Screen, t screen.Texture) bool {
+	fillPolygon(s, t, op.Points, shapeFill{op.Color, op.Gradient, op.Style})
+	return false
+}
+
+func (op PolygonOperation) WriteSVG(w io.Writer) error {
+	return writeSVGPolygon(w, op.Points, shapeFill{op.Color, op.Gradient, op.Style})
+}
+
+// fillPolygon заповнює многокутник
+func fillPolygon(s screen.Screen, t screen.Texture, pts []Point, f shapeFill) {
+	if len(pts) >= 3 {
+		f.fill(s, t, boundingBox(pts), polygonSpans(pts))
+	}
+}
+
+// writeSVGPolygon записує многокутник у форматі SVG
+func writeSVGPolygon(w io.Writer, pts []Point, f shapeFill) error {
+	if len(pts) < 3 {
+		return nil
+	}
+	fill, err := f.svg(w, boundingBox(pts))
+	if err != nil {
+		return err
+	}
+	coords := make([]string, len(pts))
+	for i, p := range pts {
+		coords[i] = fmt.Sprintf("%g,%g", p.X, p.Y)
+	}
+	_, err = fmt.Fprintf(w, `  <polygon points="%s" fill-rule="evenodd" %s/>`+"\n", strings.Join(coords, " "), fill)
+	return err
+}
@@ -0,0 +1,108 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// imageTexture текстура, яка зберігає пікселі у пам'яті, щоб тести могли перевірити результат растеризації
+type imageTexture struct {
+	img     *image.RGBA
+	uploads int
+}
+
+func newImageTexture() *imageTexture {
+	return &imageTexture{img: image.NewRGBA(image.Rectangle{Max: size})}
+}
+
+func (m *imageTexture) Release()                {}
+func (m *imageTexture) Size() image.Point       { return m.img.Rect.Size() }
+func (m *imageTexture) Bounds() image.Rectangle { return m.img.Bounds() }
+func (m *imageTexture) Upload(dp image.Point, src screen.Buffer, sr image.Rectangle) {
+	m.uploads++
+	draw.Draw(m.img, sr.Sub(sr.Min).Add(dp), src.RGBA(), sr.Min, draw.Src)
+}
+func (m *imageTexture) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	draw.Draw(m.img, dr, image.NewUniform(src), image.Point{}, op)
+}
+
+func (m *imageTexture) isSet(x, y int) bool {
+	return m.img.RGBAAt(x, y).A != 0
+}
+
+func TestEllipseOperation_Do(t *testing.T) {
+	tx := newImageTexture()
+	EllipseOperation{X: 100, Y: 100, RX: 50, RY: 20}.Do(tx)
+
+	if !tx.isSet(100, 100) || !tx.isSet(149, 100) || !tx.isSet(100, 119) {
+		t.Error("Expected points inside the ellipse to be filled")
+	}
+	if tx.isSet(151, 100) || tx.isSet(100, 121) || tx.isSet(148, 118) {
+		t.Error("Expected points outside the ellipse to stay empty")
+	}
+	if c := tx.img.RGBAAt(100, 100); c != (color.RGBA{A: 0xff}) {
+		t.Errorf("Expected default black color, got %v", c)
+	}
+}
+
+func TestPolygonOperation_Do(t *testing.T) {
+	tx := newImageTexture()
+	red := color.RGBA{R: 0xff, A: 0xff}
+	PolygonOperation{Points: []Point{{10, 10}, {110, 10}, {10, 110}}, Color: red}.Do(tx)
+
+	if tx.img.RGBAAt(20, 20) != red {
+		t.Errorf("Expected red inside the triangle, got %v", tx.img.RGBAAt(20, 20))
+	}
+	if tx.isSet(100, 100) || tx.isSet(5, 50) {
+		t.Error("Expected points outside the triangle to stay empty")
+	}
+}
+
+func TestLineOperation_Do(t *testing.T) {
+	tx := newImageTexture()
+	LineOperation{X1: 10, Y1: 50, X2: 200, Y2: 50, Width: 4}.Do(tx)
+
+	for y := 48; y < 52; y++ {
+		if !tx.isSet(100, y) {
+			t.Errorf("Expected pixel (100, %d) to be part of the line", y)
+		}
+	}
+	if tx.isSet(100, 47) || tx.isSet(100, 52) || tx.isSet(201, 50) {
+		t.Error("Line is wider or longer than expected")
+	}
+}
+
+func TestShapes_ClippedToTexture(t *testing.T) {
+	tx := newImageTexture()
+	EllipseOperation{X: 0, Y: 0, RX: 100, RY: 100}.Do(tx)
+	PolygonOperation{Points: []Point{{-100, 700}, {900, 700}, {900, 900}}}.Do(tx)
+
+	if !tx.isSet(0, 0) || !tx.isSet(799, 799) {
+		t.Error("Expected shapes partially outside the texture to be drawn")
+	}
+}
+
+func TestShapes_WriteSVG(t *testing.T) {
+	scene := Scene{Operations: []Operation{
+		EllipseOperation{X: 100, Y: 100, RX: 50, RY: 20, Color: color.RGBA{R: 0xff, A: 0xff}},
+		PolygonOperation{Points: []Point{{10, 10}, {110, 10}, {10, 110}}},
+	}}
+
+	var out strings.Builder
+	if err := scene.WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for _, e := range []string{
+		`<ellipse cx="100" cy="100" rx="50" ry="20" fill="#ff0000"/>`,
+		`<polygon points="10,10 110,10 10,110" fill-rule="evenodd" fill="#000000"/>`,
+	} {
+		if !strings.Contains(out.String(), e) {
+			t.Errorf("Expected %q in SVG output:\n%s", e, out.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package painter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// Style задає, як фігура змішується з уже намальованим вмістом текстури.
+type Style struct {
+	Transparency float64 // Прозорість від 0 (непрозора фігура, за замовчуванням) до 1 (фігура невидима)
+	Blend        draw.Op // Режим змішування: screen.Over (за замовчуванням) або screen.Src, який замінює вміст
+}
+
+// opacity повертає непрозорість фігури
+func (s Style) opacity() float64 {
+	return 1 - min(max(s.Transparency, 0), 1)
+}
+
+// apply повертає колір з урахуванням непрозорості фігури
+func (s Style) apply(c color.Color) color.Color {
+	k := s.opacity()
+	if k == 1 {
+		return c
+	}
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{
+		R: uint16(float64(r) * k), G: uint16(float64(g) * k), B: uint16(float64(b) * k), A: uint16(float64(a) * k),
+	}
+}
+
+// fill заповнює прямокутник кольором з урахуванням стилю
+func (s Style) fill(t screen.Texture, r image.Rectangle, c color.Color) {
+	t.Fill(r, s.apply(c), s.op(c))
+}
+
+// op повертає операцію змішування для кольору. Для непрозорих кольорів Over та Src дають однаковий результат,
+// тому в такому разі використовується простіша Src.
+func (s Style) op(c color.Color) draw.Op {
+	if s.Blend == screen.Src {
+		return screen.Src
+	}
+	if _, _, _, a := s.apply(c).RGBA(); a == 0xffff {
+		return screen.Src
+	}
+	return screen.Over
+}
+
+// svg повертає додаткові атрибути SVG для стилю
+func (s Style) svg() string {
+	if k := s.opacity(); k != 1 {
+		return fmt.Sprintf(` opacity="%.3f"`, k)
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package painter
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func TestStyle_OverBlendsWithBackground(t *testing.T) {
+	tx := newImageTexture()
+	tx.Fill(tx.Bounds(), color.White, screen.Src)
+
+	RectOperation{X1: 0, Y1: 0, X2: 10, Y2: 10, Style: Style{Transparency: 0.5}}.Do(tx)
+	if c := tx.img.RGBAAt(5, 5); c.R < 0x7e || c.R > 0x81 || c.A != 0xff {
+		t.Errorf("Expected black rect at 50%% opacity to blend into grey, got %v", c)
+	}
+
+	semi := color.NRGBA{R: 0xff, A: 0x80}
+	EllipseOperation{X: 100, Y: 100, RX: 10, RY: 10, Color: semi}.Do(tx)
+	if c := tx.img.RGBAAt(100, 100); c.R != 0xff || c.G < 0x7e || c.G > 0x81 {
+		t.Errorf("Expected semi-transparent red to blend into pink, got %v", c)
+	}
+}
+
+func TestStyle_FullyTransparent(t *testing.T) {
+	tx := newImageTexture()
+	tx.Fill(tx.Bounds(), color.White, screen.Src)
+
+	RectOperation{X1: 0, Y1: 0, X2: 10, Y2: 10, Style: Style{Transparency: 1}}.Do(tx)
+	if c := tx.img.RGBAAt(5, 5); c != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("Expected fully transparent rect to leave the background, got %v", c)
+	}
+}
+
+func TestStyle_SrcReplacesBackground(t *testing.T) {
+	tx := newImageTexture()
+	tx.Fill(tx.Bounds(), color.White, screen.Src)
+
+	FigureOperation{X: 400, Y: 400, Style: Style{Transparency: 0.5, Blend: screen.Src}}.Do(tx)
+	if c := tx.img.RGBAAt(400, 350); c.A < 0x7e || c.A > 0x81 {
+		t.Errorf("Expected figure to replace background with semi-transparent yellow, got %v", c)
+	}
+}
+
+func TestStyle_GradientOpacity(t *testing.T) {
+	tx := newImageTexture()
+	tx.Fill(tx.Bounds(), color.White, screen.Src)
+
+	g := &Gradient{From: color.Black, To: color.Black}
+	PolygonOperation{
+		Points: []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, Gradient: g, Style: Style{Transparency: 0.5},
+	}.DoScreen(bufferScreen{}, tx)
+
+	if tx.uploads != 0 {
+		t.Error("Expected semi-transparent gradient to be blended instead of uploaded")
+	}
+	if c := tx.img.RGBAAt(5, 5); c.R < 0x7e || c.R > 0x81 {
+		t.Errorf("Expected gradient at 50%% opacity to blend into grey, got %v", c)
+	}
+	if c := tx.img.RGBAAt(15, 5); c != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("Expected background outside the polygon to stay white, got %v", c)
+	}
+}
+
+func TestStyle_WriteSVG(t *testing.T) {
+	var out strings.Builder
+	if err := writeSVGRect(&out, image.Rect(0, 0, 10, 10), color.NRGBA{A: 0x80}, Style{Transparency: 0.75}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !strings.Contains(out.String(), `fill="#000000" fill-opacity="0.502" opacity="0.250"`) {
+		t.Errorf("Unexpected SVG output: %s", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+package painter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Scene описує поточний стан сцени, який можна експортувати у векторному форматі.
+type Scene struct {
+	Background color.Color // Колір фону, nil якщо фон ще не задано
+	Operations []Operation // Операції малювання у порядку їх виконання
+	Size       image.Point // Розмір полотна; якщо не заданий, використовується 800×800
+}
+
+// SVGElement реалізується операціями, які можуть бути представлені у вигляді елементів SVG.
+type SVGElement interface {
+	WriteSVG(w io.Writer) error
+}
+
+// WriteSVG записує сцену у форматі SVG. Геометрія збігається з растровим виводом: розмір полотна дорівнює розміру
+// текстури, а координати фігур задаються у пікселях.
+func (s Scene) WriteSVG(out io.Writer) error {
+	canvas := s.Size
+	if canvas == (image.Point{}) {
+		canvas = size
+	}
+	w := &svgCanvas{Writer: out, size: canvas}
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		canvas.X, canvas.Y, canvas.X, canvas.Y)
+	if err != nil {
+		return err
+	}
+	if s.Background != nil {
+		if err := writeSVGRect(w, image.Rectangle{Max: canvas}, s.Background, Style{}); err != nil {
+			return err
+		}
+	}
+	for _, op := range s.Operations {
+		if el, ok := op.(SVGElement); ok {
+			if err := el.WriteSVG(w); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = io.WriteString(w, "</svg>\n")
+	return err
+}
+
+// svgCanvas передає елементам сцени розмір полотна, не змінюючи інтерфейс SVGElement
+type svgCanvas struct {
+	io.Writer
+	size image.Point
+}
+
+// canvasSize повертає розмір полотна, для якого записується SVG
+func canvasSize(w io.Writer) image.Point {
+	if c, ok := w.(*svgCanvas); ok {
+		return c.size
+	}
+	return size
+}
+
+func (op RectOperation) WriteSVG(w io.Writer) error {
+	return writeSVGRect(w, image.Rect(int(op.X1), int(op.Y1), int(op.X2), int(op.Y2)), shapeColor(op.Color), op.Style)
+}
+
+func (op FigureOperation) WriteSVG(w io.Writer) error {
+	if op.Shape != nil || op.transformed() {
+		for _, part := range op.parts(canvasSize(w)) {
+			if err := writeSVGPolygon(w, part.Points, shapeFill{Color: part.Color, Style: op.Style}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, r := range op.rects(canvasSize(w)) {
+		if err := writeSVGRect(w, r, op.color(), op.Style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSVGRect записує прямокутник, попередньо обрізаючи його межами полотна, як це робить Fill на текстурі
+func writeSVGRect(w io.Writer, r image.Rectangle, c color.Color, s Style) error {
+	r = r.Intersect(image.Rectangle{Max: canvasSize(w)})
+	if r.Empty() {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, `  <rect x="%d" y="%d" width="%d" height="%d" %s%s/>`+"\n",
+		r.Min.X, r.Min.Y, r.Dx(), r.Dy(), svgFill(c), s.svg())
+	return err
+}
+
+// svgFill повертає атрибути заливки для заданого кольору
+func svgFill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+	if n.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(n.A)/0xff)
+	}
+	return fill
+}
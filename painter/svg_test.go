@@ -0,0 +1,58 @@
+package painter
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestScene_WriteSVG(t *testing.T) {
+	scene := Scene{
+		Background: color.White,
+		Operations: []Operation{
+			RectOperation{X1: 100, Y1: 100, X2: 200, Y2: 300},
+			FigureOperation{X: 400, Y: 400},
+			UpdateOp,
+		},
+	}
+
+	var out strings.Builder
+	if err := scene.WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	svg := out.String()
+
+	expected := []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="800" height="800" viewBox="0 0 800 800">`,
+		`<rect x="0" y="0" width="800" height="800" fill="#ffffff"/>`,
+		`<rect x="100" y="100" width="100" height="200" fill="#000000"/>`,
+		`<rect x="200" y="250" width="400" height="150" fill="#ffff00"/>`,
+		`<rect x="320" y="400" width="160" height="150" fill="#ffff00"/>`,
+		`</svg>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Expected %q in SVG output:\n%s", e, svg)
+		}
+	}
+	if n := strings.Count(svg, "<rect"); n != 4 {
+		t.Errorf("Expected 4 rect elements, got %d", n)
+	}
+}
+
+func TestScene_WriteSVGClipsToCanvas(t *testing.T) {
+	scene := Scene{Operations: []Operation{FigureOperation{X: 0, Y: 0}}}
+
+	var out strings.Builder
+	if err := scene.WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	svg := out.String()
+
+	if !strings.Contains(svg, `<rect x="0" y="0" width="80" height="150" fill="#ffff00"/>`) {
+		t.Errorf("Expected clipped vertical rect in SVG output:\n%s", svg)
+	}
+	if strings.Count(svg, "<rect") != 1 {
+		t.Errorf("Expected horizontal rect outside of canvas to be skipped:\n%s", svg)
+	}
+}
@@ -0,0 +1,151 @@
+package painter
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextAlign визначає вирівнювання тексту відносно точки прив'язки.
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextOperation малює рядок тексту UTF-8 шрифтом Go Regular, який містить, зокрема, кириличні символи.
+// Точка (X, Y) задає базову лінію тексту, а горизонтальне положення залежить від вирівнювання.
+type TextOperation struct {
+	X, Y  float64
+	Size  float64 // Розмір шрифту у пікселях
+	Text  string
+	Align TextAlign
+	Color color.Color
+	Style
+}
+
+func (op TextOperation) Do(t screen.Texture) bool {
+	facesMu.Lock()
+	defer facesMu.Unlock()
+
+	face, err := textFace(op.Size)
+	if err != nil || op.Text == "" {
+		return false
+	}
+
+	width := font.MeasureString(face, op.Text).Ceil()
+	x := int(op.X)
+	switch op.Align {
+	case AlignCenter:
+		x -= width / 2
+	case AlignRight:
+		x -= width
+	}
+
+	// Текст растеризується у маску прозорості, а потім переноситься на текстуру відрізками однакової прозорості.
+	// Маска обмежується текстурою, щоб довгий рядок великим шрифтом не займав пам'ять за межами полотна.
+	metrics := face.Metrics()
+	r := image.Rect(x, int(op.Y)-metrics.Ascent.Ceil(), x+width, int(op.Y)+metrics.Descent.Ceil()).Intersect(t.Bounds())
+	if r.Empty() {
+		return false
+	}
+	mask := image.NewAlpha(r)
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(x, int(op.Y))}
+	d.DrawString(op.Text)
+
+	fillMask(t, mask, op.apply(shapeColor(op.Color)))
+	return false
+}
+
+func (op TextOperation) WriteSVG(w io.Writer) error {
+	anchor := "start"
+	switch op.Align {
+	case AlignCenter:
+		anchor = "middle"
+	case AlignRight:
+		anchor = "end"
+	}
+	_, err := fmt.Fprintf(w, `  <text x="%g" y="%g" font-family="Go, sans-serif" font-size="%g" text-anchor="%s" %s>%s</text>`+"\n",
+		op.X, op.Y, op.Size, anchor, svgFill(shapeColor(op.Color))+op.Style.svg(), html.EscapeString(op.Text))
+	return err
+}
+
+var (
+	fontOnce  sync.Once
+	fontData  *opentype.Font
+	fontErr   error
+	facesMu   sync.Mutex // Захищає кеш шрифтів; font.Face не можна використовувати одночасно з кількох горутин
+	fontFaces = map[float64]font.Face{}
+)
+
+// maxFontFaces максимальна кількість шрифтів різного розміру в кеші
+const maxFontFaces = 32
+
+// textFace повертає шрифт заданого розміру, округленого до половини пікселя. Розмір задає клієнт, тому кеш
+// обмежений: коли він заповнений, створені шрифти звільняються. Викликається під facesMu.
+func textFace(size float64) (font.Face, error) {
+	fontOnce.Do(func() {
+		fontData, fontErr = opentype.Parse(goregular.TTF)
+	})
+	if fontErr != nil {
+		return nil, fontErr
+	}
+
+	size = math.Round(size*2) / 2
+	if face, ok := fontFaces[size]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(fontData, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	if len(fontFaces) >= maxFontFaces {
+		for _, f := range fontFaces {
+			f.Close()
+		}
+		clear(fontFaces)
+	}
+	fontFaces[size] = face
+	return face, nil
+}
+
+// fillMask заповнює текстуру кольором c відповідно до маски прозорості. Сусідні пікселі з однаковою прозорістю
+// об'єднуються в один виклик Fill, а напівпрозорі краї змішуються з уже намальованим вмістом.
+func fillMask(t screen.Texture, mask *image.Alpha, c color.Color) {
+	r, g, b, a := c.RGBA()
+	bounds := mask.Bounds().Intersect(t.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; {
+			alpha := mask.AlphaAt(x, y).A
+			start := x
+			for x < bounds.Max.X && mask.AlphaAt(x, y).A == alpha {
+				x++
+			}
+			if alpha == 0 {
+				continue
+			}
+
+			k := uint32(alpha) * 0x101
+			span := color.RGBA64{
+				R: uint16(r * k / 0xffff), G: uint16(g * k / 0xffff), B: uint16(b * k / 0xffff), A: uint16(a * k / 0xffff),
+			}
+			op := screen.Over
+			if span.A == 0xffff {
+				op = screen.Src
+			}
+			t.Fill(image.Rect(start, y, x, y+1), span, op)
+		}
+	}
+}
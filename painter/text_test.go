@@ -0,0 +1,102 @@
+package painter
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestTextOperation_Do(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+
+	left := newImageTexture()
+	TextOperation{X: 100, Y: 100, Size: 32, Text: "Привіт", Color: red}.Do(left)
+
+	minX, maxX, found := 800, 0, false
+	for y := 60; y < 110; y++ {
+		for x := 0; x < 800; x++ {
+			if left.isSet(x, y) {
+				minX, maxX, found = min(minX, x), max(maxX, x), true
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Expected text to be drawn")
+	}
+	if minX < 100 || minX > 105 {
+		t.Errorf("Expected left aligned text to start at x=100, got %d", minX)
+	}
+	for y := 0; y < 800; y++ {
+		if left.isSet(50, y) || left.isSet(maxX+5, y) {
+			t.Fatal("Expected nothing to be drawn outside the text")
+		}
+	}
+
+	right := newImageTexture()
+	TextOperation{X: 100, Y: 100, Size: 32, Text: "Привіт", Align: AlignRight}.Do(right)
+	for y := 60; y < 110; y++ {
+		for x := 101; x < 800; x++ {
+			if right.isSet(x, y) {
+				t.Fatalf("Expected right aligned text to end at x=100, got pixel at %d", x)
+			}
+		}
+	}
+}
+
+func TestTextOperation_Clipped(t *testing.T) {
+	tx := newImageTexture()
+	TextOperation{X: -100, Y: 400, Size: 400, Text: strings.Repeat("W", 1000)}.Do(tx)
+	drawn := 0
+	for x := 0; x < 800; x++ {
+		if tx.isSet(x, 350) {
+			drawn++
+		}
+	}
+	if drawn == 0 {
+		t.Error("Expected the visible part of the text to be drawn")
+	}
+
+	// Текст за межами текстури не малюється зовсім
+	empty := newImageTexture()
+	TextOperation{X: 0, Y: -1000, Size: 100, Text: "hidden"}.Do(empty)
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 800; x++ {
+			if empty.isSet(x, y) {
+				t.Fatalf("Expected nothing to be drawn, got pixel at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestTextOperation_WriteSVG(t *testing.T) {
+	var out strings.Builder
+	op := TextOperation{X: 10, Y: 20, Size: 16, Text: `a < "b"`, Align: AlignCenter}
+	if err := op.WriteSVG(&out); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	expected := `<text x="10" y="20" font-family="Go, sans-serif" font-size="16" text-anchor="middle" fill="#000000">a &lt; &#34;b&#34;</text>`
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Unexpected SVG output: %s", out.String())
+	}
+}
+
+func TestTextFace_Bounded(t *testing.T) {
+	facesMu.Lock()
+	defer facesMu.Unlock()
+
+	a, err := textFace(20.1)
+	if err != nil {
+		t.Fatalf("textFace error: %v", err)
+	}
+	if b, _ := textFace(20.2); b != a {
+		t.Error("Expected close sizes to share a font face")
+	}
+	for i := 0; i < 10*maxFontFaces; i++ {
+		if _, err := textFace(10 + float64(i)); err != nil {
+			t.Fatalf("textFace error: %v", err)
+		}
+	}
+	if len(fontFaces) > maxFontFaces {
+		t.Errorf("Expected at most %d cached font faces, got %d", maxFontFaces, len(fontFaces))
+	}
+}
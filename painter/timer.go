@@ -0,0 +1,91 @@
+package painter
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Clock джерело часу для циклу подій. Дозволяє підмінити реальний час, щоб зробити тести детермінованими.
+type Clock interface {
+	Now() time.Time
+	// After повертає канал, у який надійде значення, коли мине d.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// timer операція, яка має бути виконана у заданий момент
+type timer struct {
+	at  time.Time
+	seq uint64 // порядковий номер, щоб операції з однаковим часом виконувались у порядку додавання
+	op  Operation
+}
+
+// timerHeap мінімальна купа таймерів, упорядкована за часом виконання
+type timerHeap []timer
+
+func (h timerHeap) Len() int { return len(h) }
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h timerHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *timerHeap) Push(x any)   { *h = append(*h, x.(timer)) }
+func (h *timerHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = timer{}
+	*h = old[:len(old)-1]
+	return t
+}
+
+// PostAfter додає операцію у чергу після того, як мине d за годинником Clock.
+func (l *Loop) PostAfter(d time.Duration, op Operation) {
+	l.PostAt(l.now().Add(d), op)
+}
+
+// now повертає поточний час за годинником Clock. Годинник читається з l, бо черга дізнається про Clock лише під
+// час Start.
+func (l *Loop) now() time.Time {
+	if l.Clock == nil {
+		return time.Now()
+	}
+	return l.Clock.Now()
+}
+
+// PostAt додає операцію у чергу в момент t. Операції, заплановані на момент після зупинки циклу, не виконуються.
+func (l *Loop) PostAt(t time.Time, op Operation) {
+	if op == nil {
+		return
+	}
+
+	l.mq.pushAt(t, op)
+}
+
+func (mq *messageQueue) pushAt(t time.Time, op Operation) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.seq++
+	heap.Push(&mq.timers, timer{at: t, seq: mq.seq, op: op})
+	if mq.blocked != nil {
+		close(mq.blocked)
+		mq.blocked = nil
+	}
+}
+
+// releaseTimers переносить у чергу операції, час виконання яких уже настав, та повертає затримку до наступного
+// таймера (або -1, якщо таймерів немає)
+func (mq *messageQueue) releaseTimers(now time.Time) time.Duration {
+	for len(mq.timers) > 0 {
+		if wait := mq.timers[0].at.Sub(now); wait > 0 {
+			return wait
+		}
+		mq.Queue = append(mq.Queue, heap.Pop(&mq.timers).(timer).op)
+	}
+	return -1
+}
@@ -0,0 +1,204 @@
+package painter
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// fakeClock годинник, час якого змінюється лише викликом Advance
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiting := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiting = append(waiting, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = waiting
+}
+
+func record(results chan<- string, name string) OperationFunc {
+	return func(screen.Texture) { results <- name }
+}
+
+func expectResults(t *testing.T, results <-chan string, expected ...string) {
+	t.Helper()
+	var got []string
+	for range expected {
+		select {
+		case r := <-results:
+			got = append(got, r)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %v, got %v", expected, got)
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected order: got %v, want %v", got, expected)
+	}
+}
+
+func TestLoop_PostAfterAndPostAt(t *testing.T) {
+	clock := newFakeClock()
+	l := Loop{Clock: clock}
+	l.Start(mockScreen{})
+
+	results := make(chan string, 10)
+	l.PostAfter(2*time.Second, record(results, "after 2s"))
+	l.PostAfter(time.Second, record(results, "after 1s"))
+	l.PostAt(clock.Now().Add(3*time.Second), record(results, "at 3s"))
+	l.PostAfter(time.Second, record(results, "after 1s again"))
+	l.Post(record(results, "now"))
+
+	expectResults(t, results, "now")
+
+	clock.Advance(1500 * time.Millisecond)
+	expectResults(t, results, "after 1s", "after 1s again")
+
+	clock.Advance(2 * time.Second)
+	expectResults(t, results, "after 2s", "at 3s")
+
+	l.StopAndWait()
+}
+
+func TestLoop_PostAfterBeforeStart(t *testing.T) {
+	clock := newFakeClock()
+	l := Loop{Clock: clock}
+	results := make(chan string, 10)
+	l.PostAfter(time.Second, record(results, "after 1s"))
+	l.Post(record(results, "now"))
+	l.Start(mockScreen{})
+
+	expectResults(t, results, "now")
+	clock.Advance(1500 * time.Millisecond)
+	expectResults(t, results, "after 1s")
+
+	l.StopAndWait()
+}
+
+func TestLoop_PostAfterDroppedOnStop(t *testing.T) {
+	clock := newFakeClock()
+	l := Loop{Clock: clock}
+	l.Start(mockScreen{})
+
+	executed := false
+	l.PostAfter(time.Minute, OperationFunc(func(screen.Texture) { executed = true }))
+	l.StopAndWait()
+
+	if executed {
+		t.Error("Delayed operation should not run after stop")
+	}
+}
+
+func TestLoop_AnimateWithClock(t *testing.T) {
+	clock := newFakeClock()
+	l := Loop{Clock: clock}
+	l.Start(mockScreen{})
+
+	fig := &FigureOperation{}
+	positions := make(chan float64, 100)
+	l.Post(&AnimateOperation{
+		Figures:  []*FigureOperation{fig},
+		X:        90,
+		Duration: 3 * frameInterval,
+		Frame: func() Operation {
+			return OperationFunc(func(screen.Texture) { positions <- fig.X })
+		},
+	})
+	started := make(chan string, 1)
+	l.Post(record(started, "started"))
+	expectResults(t, started, "started")
+
+	var got []float64
+	for i := 0; i < 3; i++ {
+		clock.Advance(frameInterval)
+		select {
+		case x := <-positions:
+			got = append(got, x)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for frame %d", i)
+		}
+	}
+	l.StopAndWait()
+
+	if !reflect.DeepEqual(got, []float64{30, 60, 90}) {
+		t.Errorf("Unexpected positions: %v", got)
+	}
+	if len(l.animations) != 0 {
+		t.Errorf("Expected animation to finish, got %d active", len(l.animations))
+	}
+}
+
+func TestLoop_StopAnimations(t *testing.T) {
+	clock := newFakeClock()
+	l := Loop{Clock: clock}
+	l.Start(mockScreen{})
+
+	fig := &FigureOperation{}
+	frames := make(chan float64, 100)
+	l.Post(&AnimateOperation{
+		Figures:  []*FigureOperation{fig},
+		X:        90,
+		Duration: time.Minute,
+		Frame: func() Operation {
+			return OperationFunc(func(screen.Texture) { frames <- fig.X })
+		},
+	})
+	l.Post(StopAnimationsOp)
+	stopped := make(chan string, 1)
+	l.Post(record(stopped, "stopped"))
+	expectResults(t, stopped, "stopped")
+
+	clock.Advance(10 * frameInterval)
+	done := make(chan string, 1)
+	l.Post(record(done, "done"))
+	expectResults(t, done, "done")
+	l.StopAndWait()
+
+	if len(frames) != 0 || fig.X != 0 {
+		t.Errorf("Expected the stopped animation to draw no frames, got %d frames and x=%v", len(frames), fig.X)
+	}
+	if len(l.animations) != 0 {
+		t.Errorf("Expected no active animations, got %d", len(l.animations))
+	}
+}
@@ -0,0 +1,13 @@
+#!/bin/bash
+
+curl -X POST http://localhost:17000 -d "reset"
+curl -X POST http://localhost:17000 -d "white"
+curl -X POST http://localhost:17000 -d "figure 0.0 0.0"
+curl -X POST http://localhost:17000 -d "update"
+
+while true; do
+    curl -X POST http://localhost:17000 -d "animate 0 1.0 1.0 2 ease-in-out"
+    sleep 2
+    curl -X POST http://localhost:17000 -d "animate 0 0.0 0.0 2 bounce"
+    sleep 2
+done
@@ -0,0 +1,19 @@
+#!/bin/bash
+
+# Сітка фігур, що описана одним скриптом замість генерації команд через bc
+curl -X POST http://localhost:17000 --data-binary @- <<'SCRIPT'
+reset
+white
+let n = 4
+let step = 1 / ($n + 1)
+repeat $n i {
+  repeat $n j {
+    if ($i + $j) % 2 == 0 {
+      figure ($step * ($i + 1)) ($step * ($j + 1)) scale=0.2 rot=$i*15
+    } else {
+      circle ($step * ($i + 1)) ($step * ($j + 1)) 0.05 #00f
+    }
+  }
+}
+update
+SCRIPT
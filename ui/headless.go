@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/draw"
+)
+
+// Headless реалізує screen.Screen без вікна: текстури та буфери зберігаються у пам'яті як image.RGBA. Разом з
+// Viewer дозволяє запускати painter на сервері без графічного середовища.
+type Headless struct{}
+
+func (Headless) NewBuffer(size image.Point) (screen.Buffer, error) {
+	return &memoryBuffer{rgba: image.NewRGBA(image.Rectangle{Max: size})}, nil
+}
+
+func (Headless) NewTexture(size image.Point) (screen.Texture, error) {
+	return &MemoryTexture{rgba: image.NewRGBA(image.Rectangle{Max: size})}, nil
+}
+
+func (Headless) NewWindow(*screen.NewWindowOptions) (screen.Window, error) {
+	return nil, errors.New("headless screen has no windows")
+}
+
+type memoryBuffer struct {
+	rgba *image.RGBA
+}
+
+func (b *memoryBuffer) Release()                {}
+func (b *memoryBuffer) Size() image.Point       { return b.rgba.Rect.Size() }
+func (b *memoryBuffer) Bounds() image.Rectangle { return b.rgba.Rect }
+func (b *memoryBuffer) RGBA() *image.RGBA       { return b.rgba }
+
+// MemoryTexture текстура Headless, вміст якої можна прочитати.
+type MemoryTexture struct {
+	mu   sync.Mutex
+	rgba *image.RGBA
+}
+
+func (t *MemoryTexture) Release()                {}
+func (t *MemoryTexture) Size() image.Point       { return t.rgba.Rect.Size() }
+func (t *MemoryTexture) Bounds() image.Rectangle { return t.rgba.Rect }
+
+func (t *MemoryTexture) Upload(dp image.Point, src screen.Buffer, sr image.Rectangle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	draw.Draw(t.rgba, sr.Sub(sr.Min).Add(dp), src.RGBA(), sr.Min, draw.Src)
+}
+
+func (t *MemoryTexture) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	draw.Draw(t.rgba, dr, image.NewUniform(src), image.Point{}, op)
+}
+
+// Snapshot повертає копію поточного вмісту текстури.
+func (t *MemoryTexture) Snapshot() *image.RGBA {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := image.NewRGBA(t.rgba.Rect)
+	copy(res.Pix, t.rgba.Pix)
+	return res
+}
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// Viewer показує полотно у браузері замість вікна shiny. Він отримує кадри як painter.Receiver та обслуговує HTTP
+// запити: сторінку переглядача, потік MJPEG (stream) та знімок останнього кадру у PNG (frame.png). Прочитати можна
+// лише текстури Headless, тому Viewer використовується разом з цим екраном. Нульове значення готове до використання.
+type Viewer struct {
+	Quality int // Якість JPEG у потоці від 1 до 100; 0 означає jpeg.DefaultQuality
+
+	mu      sync.Mutex
+	frame   *viewerFrame
+	changed chan struct{} // Закривається, коли з'являється новий кадр
+	warned  bool
+}
+
+// viewerFrame незмінний кадр, який кодується у JPEG один раз для всіх клієнтів
+type viewerFrame struct {
+	img  *image.RGBA
+	once sync.Once
+	jpeg []byte
+}
+
+func (f *viewerFrame) encodeJPEG(quality int) []byte {
+	f.once.Do(func() {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, f.img, &jpeg.Options{Quality: quality}); err != nil {
+			log.Printf("Failed to encode frame: %s", err)
+			return
+		}
+		f.jpeg = buf.Bytes()
+	})
+	return f.jpeg
+}
+
+// Update зберігає копію кадру та повідомляє про нього клієнтів потоку.
+func (v *Viewer) Update(t screen.Texture) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	mt, ok := t.(*MemoryTexture)
+	if !ok {
+		if !v.warned {
+			log.Printf("Viewer cannot read %T textures: use the headless screen", t)
+			v.warned = true
+		}
+		return
+	}
+
+	// Текстура одразу використовується циклом для наступного кадру, тому її вміст копіюється
+	v.frame = &viewerFrame{img: mt.Snapshot()}
+	if v.changed != nil {
+		close(v.changed)
+		v.changed = nil
+	}
+}
+
+// current повертає останній кадр та канал, який закриється з появою наступного
+func (v *Viewer) current() (*viewerFrame, chan struct{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.changed == nil {
+		v.changed = make(chan struct{})
+	}
+	return v.frame, v.changed
+}
+
+func (v *Viewer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/", "":
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(rw, viewerPage)
+	case "/stream":
+		v.stream(rw, r)
+	case "/frame.png":
+		frame, _ := v.current()
+		if frame == nil {
+			http.Error(rw, "no frame yet", http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Set("Content-Type", "image/png")
+		rw.Header().Set("Cache-Control", "no-store")
+		if err := png.Encode(rw, frame.img); err != nil {
+			log.Printf("Failed to write frame: %s", err)
+		}
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+// stream надсилає кадри як multipart/x-mixed-replace. Клієнт завжди отримує останній кадр, а проміжні кадри, які
+// він не встиг прочитати, пропускаються.
+func (v *Viewer) stream(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	quality := v.Quality
+	if quality == 0 {
+		quality = jpeg.DefaultQuality
+	}
+
+	// Потік кадрів триває довше за ReadTimeout та WriteTimeout сервера, тому обмеження часу для нього знімаються
+	rc := http.NewResponseController(rw)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+	mw := multipart.NewWriter(rw)
+	rw.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mw.Boundary())
+	rw.Header().Set("Cache-Control", "no-store")
+	for {
+		frame, changed := v.current()
+		if frame != nil {
+			data := frame.encodeJPEG(quality)
+			part, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":   {"image/jpeg"},
+				"Content-Length": {strconv.Itoa(len(data))},
+			})
+			if err == nil {
+				_, err = part.Write(data)
+			}
+			if err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+const viewerPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Painter</title>
+<style>
+  body { margin: 0; background: #222; display: flex; align-items: center; justify-content: center; height: 100vh; }
+  img { max-width: 100vmin; max-height: 100vmin; image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<img id="canvas" alt="painter canvas">
+<script>
+  // Параметри сторінки, зокрема access_token, передаються потоку, бо тег img не надсилає заголовків
+  document.getElementById("canvas").src = "stream" + location.search;
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+func TestHeadless_Texture(t *testing.T) {
+	var s Headless
+	tx, _ := s.NewTexture(image.Pt(4, 4))
+	buf, _ := s.NewBuffer(image.Pt(2, 2))
+	buf.RGBA().Set(1, 1, color.RGBA{B: 255, A: 255})
+
+	tx.Fill(tx.Bounds(), color.White, draw.Src)
+	tx.Fill(image.Rect(0, 0, 1, 1), color.RGBA{R: 128, A: 128}, draw.Over)
+	tx.Upload(image.Pt(2, 2), buf, buf.Bounds())
+
+	img := tx.(*MemoryTexture).Snapshot()
+	if c := img.RGBAAt(0, 0); c != (color.RGBA{R: 255, G: 127, B: 127, A: 255}) {
+		t.Errorf("Expected blended pixel, got %v", c)
+	}
+	if c := img.RGBAAt(3, 3); c != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("Expected uploaded pixel, got %v", c)
+	}
+	if c := img.RGBAAt(1, 1); c != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("Expected white pixel, got %v", c)
+	}
+}
+
+func TestViewer(t *testing.T) {
+	var viewer Viewer
+	server := httptest.NewServer(&viewer)
+	defer server.Close()
+
+	resp, _ := http.Get(server.URL + "/frame.png")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first frame, got %d", resp.StatusCode)
+	}
+
+	tx, _ := Headless{}.NewTexture(image.Pt(8, 8))
+	tx.Fill(tx.Bounds(), color.RGBA{G: 255, A: 255}, draw.Src)
+	viewer.Update(tx)
+	tx.Fill(tx.Bounds(), color.Black, draw.Src) // Кадр вже скопійовано
+
+	resp, err := http.Get(server.URL + "/frame.png")
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	img, err := png.Decode(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if r, g, _, _ := img.At(4, 4).RGBA(); r != 0 || g != 0xffff {
+		t.Errorf("Expected green frame, got %v", img.At(4, 4))
+	}
+
+	resp, err = http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	defer resp.Body.Close()
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Invalid content type: %v", err)
+	}
+	mr := multipart.NewReader(bufio.NewReader(resp.Body), params["boundary"])
+	for i := range 2 {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("Frame %d: %v", i, err)
+		}
+		if ct := part.Header.Get("Content-Type"); ct != "image/jpeg" {
+			t.Errorf("Frame %d has content type %q", i, ct)
+		}
+		if i == 0 {
+			viewer.Update(tx)
+		}
+	}
+}
@@ -20,6 +20,7 @@ type Visualizer struct {
 	Title         string
 	Debug         bool
 	OnScreenReady func(s screen.Screen)
+	OnKey         func(e key.Event) // Викликається для кожної клавіші, крім Esc, яка закриває вікно
 
 	w    screen.Window
 	tx   chan screen.Texture
@@ -42,6 +43,9 @@ func (pw *Visualizer) Update(t screen.Texture) {
 	pw.tx <- t
 }
 
+// ShowsFrames позначає вікно як painter.Display: отриману текстуру воно перемальовує до наступного кадру.
+func (pw *Visualizer) ShowsFrames() {}
+
 func (pw *Visualizer) run(s screen.Screen) {
 	w, err := s.NewWindow(&screen.NewWindowOptions{
 		Title:  pw.Title,
@@ -116,6 +120,11 @@ func (pw *Visualizer) handleEvent(e any, t screen.Texture) {
 	case error:
 		log.Printf("ERROR: %s", e)
 
+	case key.Event:
+		if pw.OnKey != nil {
+			pw.OnKey(e)
+		}
+
 	case mouse.Event:
 		if e.Button == mouse.ButtonLeft && e.Direction == mouse.DirPress {
 			pw.mousePos = image.Point{X: int(e.X), Y: int(e.Y)}